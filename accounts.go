@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/hd"
+	"github.com/ottosch/pick-private/keys"
+)
+
+var purposeNames = map[uint32]string{
+	hd.PurposeLegacy:       "BIP44 Legacy",
+	hd.PurposeNestedSegwit: "BIP49 P2SH-Segwit",
+	hd.PurposeNativeSegwit: "BIP84 SegWit",
+	hd.PurposeTaproot:      "BIP86 Taproot",
+}
+
+func printAccounts() {
+	if !rootKey.IsPrivate() || rootKey.Depth() != 0 {
+		fmt.Fprintln(os.Stderr, "-accounts requires a master private key")
+		os.Exit(1)
+	}
+
+	for _, purpose := range hd.Purposes {
+		if !purposeSupported(rootKey.Params(), purpose) {
+			continue
+		}
+
+		account, err := rootKey.Account(purpose, uint32(accountIndex))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		accountPath := hd.AccountPath(purpose, rootKey.Params(), uint32(accountIndex))
+		fmt.Printf("[%s]\n", purposeNames[purpose])
+		fmt.Printf("   Path: %s\n", accountPath)
+		fmt.Printf("   xpub: %s\n", account.Neuter().StringSLIP132(purpose))
+		fmt.Println()
+
+		for change, name := range []string{"Receive", "Change"} {
+			fmt.Printf("%s:\n", name)
+			for i := uint(0); i < accountCount; i++ {
+				child, err := account.Derive(fmt.Sprintf("%d/%d", change, i))
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					os.Exit(1)
+				}
+
+				key, _ := child.PrivateKey()
+				fmt.Printf("%s/%d/%d %s\n", accountPath, change, i, accountAddress(&key, purpose))
+			}
+			fmt.Println()
+		}
+	}
+}
+
+func purposeSupported(params *chaincfg.Params, purpose uint32) bool {
+	switch purpose {
+	case hd.PurposeNestedSegwit, hd.PurposeNativeSegwit:
+		return params.SegWit
+	case hd.PurposeTaproot:
+		return params.Taproot
+	default:
+		return true
+	}
+}
+
+func accountAddress(key *keys.PrivateKey, purpose uint32) string {
+	switch purpose {
+	case hd.PurposeNestedSegwit:
+		return key.ToAddressSegWitCompat()
+	case hd.PurposeNativeSegwit:
+		return key.ToAddressSegWit()
+	case hd.PurposeTaproot:
+		return key.ToAddressTaproot()
+	default:
+		return key.ToAddressLegacy()
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ottosch/pick-private/address"
+)
+
+func runAddress(args []string) {
+	flags := flag.NewFlagSet("address", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s address address\n", os.Args[0])
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s address bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4\n", os.Args[0])
+		fmt.Printf("  %s address 3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN\n", os.Args[0])
+		fmt.Printf("  %s address ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9\n", os.Args[0])
+	}
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	decoded, err := address.Decode(positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[Address]")
+	fmt.Printf("        Address: %s\n", positional[0])
+	fmt.Printf("        Network: %s %s\n", decoded.Params.Coin, decoded.Params.Name)
+	fmt.Printf("           Type: %s\n", decoded.Type)
+	if decoded.WitnessVersion >= 0 {
+		fmt.Printf("Witness version: %d\n", decoded.WitnessVersion)
+		fmt.Printf("        Program: %x\n", decoded.Program)
+	} else {
+		fmt.Printf("           Hash: %x\n", decoded.Program)
+	}
+	fmt.Printf("         Script: %x\n", decoded.Script)
+}
@@ -0,0 +1,121 @@
+package address
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bech32"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/script"
+)
+
+// Address types
+const (
+	TypeP2PKH         = "P2PKH"
+	TypeP2SH          = "P2SH"
+	TypeP2WPKH        = "P2WPKH"
+	TypeP2WSH         = "P2WSH"
+	TypeP2TR          = "P2TR"
+	TypeWitnessFuture = "Witness (unknown version)"
+)
+
+// Address is a decoded address
+type Address struct {
+	Params         *chaincfg.Params // the first registered network of the prefix, Bitcoin first
+	Type           string
+	WitnessVersion int // -1 for legacy addresses
+	Program        []byte
+	Script         []byte
+}
+
+// Decode decodes a base58check or bech32/bech32m address of any registered network
+func Decode(addr string) (*Address, error) {
+	lower := strings.ToLower(addr)
+	if separator := strings.LastIndex(lower, "1"); separator > 0 {
+		if params, err := chaincfg.LookupHRP(lower[:separator]); err == nil {
+			return decodeSegwit(params, addr)
+		}
+	}
+
+	version, payload, err := base58.CheckDecode(addr, 1)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %v", addr, err)
+	}
+
+	params, scriptHash, err := chaincfg.LookupAddressID(version[0])
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, addr)
+	}
+	if len(payload) != 20 {
+		return nil, fmt.Errorf("invalid address hash length %d: %s", len(payload), addr)
+	}
+
+	addressType, scriptPubKey := TypeP2PKH, script.P2PKH(payload)
+	if scriptHash {
+		addressType, scriptPubKey = TypeP2SH, script.P2SH(payload)
+	}
+
+	return &Address{
+		Params:         params,
+		Type:           addressType,
+		WitnessVersion: -1,
+		Program:        payload,
+		Script:         scriptPubKey,
+	}, nil
+}
+
+func decodeSegwit(params *chaincfg.Params, addr string) (*Address, error) {
+	version, data, err := bech32.SegwitAddrDecode(params.Bech32HRP, addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %v", addr, err)
+	}
+
+	program := make([]byte, len(data))
+	for i, b := range data {
+		program[i] = byte(b)
+	}
+
+	addressType := TypeWitnessFuture
+	switch {
+	case version == 0 && len(program) == 20:
+		addressType = TypeP2WPKH
+	case version == 0 && len(program) == 32:
+		addressType = TypeP2WSH
+	case version == 1 && len(program) == 32:
+		addressType = TypeP2TR
+	}
+
+	return &Address{
+		Params:         params,
+		Type:           addressType,
+		WitnessVersion: version,
+		Program:        program,
+		Script:         script.WitnessProgram(version, program),
+	}, nil
+}
+
+// FromScript encodes a standard scriptPubKey as an address of the given network
+func FromScript(scriptPubKey []byte, params *chaincfg.Params) (string, error) {
+	class, data := script.Classify(scriptPubKey)
+	switch class {
+	case script.ClassPubKeyHash:
+		return base58.CheckEncode([]byte{params.PubKeyHashAddrID}, data[0]), nil
+	case script.ClassScriptHash:
+		return base58.CheckEncode([]byte{params.ScriptHashAddrID}, data[0]), nil
+	case script.ClassWitnessV0KeyHash, script.ClassWitnessV0Script, script.ClassWitnessV1Taproot, script.ClassWitnessUnknown:
+		version := 0
+		if scriptPubKey[0] != script.Op0 {
+			version = int(scriptPubKey[0]-script.Op1) + 1
+		}
+
+		program := make([]int, len(data[0]))
+		for i, b := range data[0] {
+			program[i] = int(b)
+		}
+
+		return bech32.SegwitAddrEncode(params.Bech32HRP, version, program)
+	}
+
+	return "", fmt.Errorf("no address for script %x", scriptPubKey)
+}
@@ -0,0 +1,85 @@
+package address_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/chaincfg"
+)
+
+type decodeTestData struct {
+	input          string
+	params         *chaincfg.Params
+	addressType    string
+	witnessVersion int
+	script         string
+}
+
+var decodeTests = []decodeTestData{
+	{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", chaincfg.MainNet, address.TypeP2PKH, -1, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+	{"3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN", chaincfg.MainNet, address.TypeP2SH, -1, "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487"},
+	{"mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", chaincfg.TestNet3, address.TypeP2PKH, -1, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+	{"BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", chaincfg.MainNet, address.TypeP2WPKH, 0, "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", chaincfg.TestNet3, address.TypeP2WSH, 0, "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"},
+	{"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr", chaincfg.MainNet, address.TypeP2TR, 1, "5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c"},
+	{"bc1zw508d6qejxtdg4y5r3zarvaryvaxxpcs", chaincfg.MainNet, address.TypeWitnessFuture, 2, "5210751e76e8199196d454941c45d1b3a323"},
+	{"bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080", chaincfg.RegTest, address.TypeP2WPKH, 0, "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", chaincfg.LitecoinMainNet, address.TypeP2PKH, -1, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+	{"MR8UQSBr5ULwWheBHznrHk2jxyxkHQu8vB", chaincfg.LitecoinMainNet, address.TypeP2SH, -1, "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487"},
+	{"ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9", chaincfg.LitecoinMainNet, address.TypeP2WPKH, 0, "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"DFpN6QqFfUm3gKNaxN6tNcab1FArL9cZLE", chaincfg.DogecoinMainNet, address.TypeP2PKH, -1, "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+}
+
+var invalidAddresses = []string{
+	"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMJ",                             // bad checksum
+	"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5",                     // bad bech32 checksum
+	"bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxq7sd6n4", // not a real checksum
+	"5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf",            // WIF, not an address
+}
+
+func TestDecode(t *testing.T) {
+	for _, test := range decodeTests {
+		result, err := address.Decode(test.input)
+
+		switch {
+		case err != nil:
+			t.Errorf("Decode for %s FAILED: %v\n", test.input, err)
+		case result.Params != test.params || result.Type != test.addressType || result.WitnessVersion != test.witnessVersion:
+			t.Errorf("Decode for %s FAILED. Expected %s %s %s %d, got %s %s %s %d\n", test.input,
+				test.params.Coin, test.params.Name, test.addressType, test.witnessVersion,
+				result.Params.Coin, result.Params.Name, result.Type, result.WitnessVersion)
+		case hex.EncodeToString(result.Script) != test.script:
+			t.Errorf("Decode for %s FAILED. Expected script %s, got %x\n", test.input, test.script, result.Script)
+		default:
+			t.Logf("Decode passed: %s, %s\n", test.input, test.script)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, test := range invalidAddresses {
+		if _, err := address.Decode(test); err == nil {
+			t.Errorf("Decode for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("Decode for %s failed: %v\n", test, err)
+		}
+	}
+}
+
+func TestFromScript(t *testing.T) {
+	for _, test := range decodeTests {
+		script, _ := hex.DecodeString(test.script)
+		result, err := address.FromScript(script, test.params)
+
+		switch {
+		case err != nil:
+			t.Errorf("FromScript for %s FAILED: %v\n", test.script, err)
+		case result != test.input && result != strings.ToLower(test.input):
+			t.Errorf("FromScript for %s FAILED. Expected %s, got %s\n", test.script, test.input, result)
+		default:
+			t.Logf("FromScript passed: %s, %s\n", test.script, result)
+		}
+	}
+}
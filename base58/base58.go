@@ -1,6 +1,7 @@
 package base58
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -12,42 +13,48 @@ import (
 
 const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 
-// Encode encodes argument string to base58check
+// Encode encodes argument hex string to base58
 func Encode(hexString string) string {
-	decimalData := new(big.Int)
-	decimalData, _ = decimalData.SetString(hexString, 16)
+	if len(hexString)%2 != 0 {
+		hexString = "0" + hexString
+	}
+
+	data, _ := hex.DecodeString(hexString)
+	return EncodeBytes(data)
+}
 
+// EncodeBytes encodes bytes to base58, with one '1' per leading zero byte
+func EncodeBytes(data []byte) string {
+	decimalData := new(big.Int).SetBytes(data)
 	divisor, zero := big.NewInt(58), big.NewInt(0)
 
-	var encoded string
+	var encoded []byte
 	for decimalData.Cmp(zero) > 0 {
 		mod := new(big.Int)
 		decimalData.DivMod(decimalData, divisor, mod)
-		encoded = string(alphabet[mod.Int64()]) + encoded
+		encoded = append(encoded, alphabet[mod.Int64()])
 	}
 
-	var leadingZeros int
-	for _, char := range hexString {
-		if char == '0' {
-			leadingZeros++
-		} else {
+	for _, b := range data {
+		if b != 0 {
 			break
 		}
+		encoded = append(encoded, '1')
 	}
 
-	return strings.Repeat("1", leadingZeros/2) + encoded
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
 }
 
-// Decode decodes argument WIF (compressed or uncompressed) to big.Int private key.
-func Decode(wif string) (*big.Int, error) {
-	compressed := wif[0] == 'K' || wif[0] == 'L' || wif[0] == 'c'
-
+// DecodeBytes decodes base58 to bytes, with one zero byte per leading '1'
+func DecodeBytes(input string) ([]byte, error) {
 	multiplier := big.NewInt(58)
 	total := new(big.Int)
-	for _, c := range wif {
+	for _, c := range input {
 		if !strings.ContainsRune(alphabet, c) {
-			err := fmt.Errorf("invalid character in WIF: %c\n", c)
-			return new(big.Int), err
+			return nil, fmt.Errorf("invalid base58 character: %c", c)
 		}
 
 		total.Mul(total, multiplier)
@@ -55,34 +62,65 @@ func Decode(wif string) (*big.Int, error) {
 		total.Add(total, big.NewInt(digit))
 	}
 
-	if err := verifyChecksum(total.Bytes()); err != nil {
-		return new(big.Int), err
+	var leadingZeros int
+	for _, c := range input {
+		if c != '1' {
+			break
+		}
+		leadingZeros++
 	}
 
-	bytes := total.Bytes()[1 : len(total.Bytes())-4]
-	if compressed {
-		bytes = bytes[:len(bytes)-1]
-	}
+	return append(make([]byte, leadingZeros), total.Bytes()...), nil
+}
 
-	privBigInt, _ := new(big.Int).SetString(hex.EncodeToString(bytes), 16)
-	return privBigInt, nil
+// CheckEncode encodes version bytes and payload to base58check
+func CheckEncode(version, payload []byte) string {
+	data := make([]byte, 0, len(version)+len(payload)+4)
+	data = append(data, version...)
+	data = append(data, payload...)
+	data = append(data, crypto.Hash256(data)[:4]...)
+	return EncodeBytes(data)
 }
 
-func verifyChecksum(private []byte) error {
-	if len(private) <= 4 {
-		return errors.New("invalid WIF: too short")
+// CheckDecode decodes a base58check string, verifying and stripping the 4-byte checksum.
+// The first versionLen bytes are returned as the version, the rest as the payload.
+func CheckDecode(input string, versionLen int) ([]byte, []byte, error) {
+	decoded, err := DecodeBytes(input)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	extendedPrivate := private[:len(private)-4]
+	if len(decoded) < versionLen+4 || len(decoded) <= 4 {
+		return nil, nil, errors.New("invalid base58check: too short")
+	}
 
-	inputChecksum := private[len(private)-4:]
-	expectedChecksum := crypto.Hash256(extendedPrivate)[:4]
+	data := decoded[:len(decoded)-4]
+	inputChecksum := decoded[len(decoded)-4:]
+	expectedChecksum := crypto.Hash256(data)[:4]
+	if !bytes.Equal(inputChecksum, expectedChecksum) {
+		return nil, nil, fmt.Errorf("invalid base58check checksum, expected %x, got %x", expectedChecksum, inputChecksum)
+	}
 
-	for i := range inputChecksum {
-		if inputChecksum[i] != expectedChecksum[i] {
-			return fmt.Errorf("invalid WIF checksum, expected %x, got %x\n", expectedChecksum, inputChecksum)
-		}
+	return data[:versionLen], data[versionLen:], nil
+}
+
+// Decode decodes argument WIF (compressed or uncompressed) to big.Int private key.
+// The version byte must be the network's, like 0x80 for mainnet.
+func Decode(wif string, version byte) (*big.Int, error) {
+	prefix, payload, err := CheckDecode(wif, 1)
+	if err != nil {
+		return new(big.Int), fmt.Errorf("invalid WIF: %v", err)
+	}
+	if prefix[0] != version {
+		return new(big.Int), fmt.Errorf("invalid WIF: version %02x, expected %02x", prefix[0], version)
+	}
+
+	switch {
+	case len(payload) == 33 && payload[32] == 0x01:
+		payload = payload[:32]
+	case len(payload) != 32:
+		return new(big.Int), fmt.Errorf("invalid WIF: unexpected length %d", len(payload))
 	}
 
-	return nil
+	return new(big.Int).SetBytes(payload), nil
 }
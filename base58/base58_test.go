@@ -1,6 +1,7 @@
 package base58_test
 
 import (
+	"encoding/hex"
 	"math/big"
 	"testing"
 
@@ -13,8 +14,9 @@ type encodeTestData struct {
 }
 
 type decodeTestData struct {
-	input  string
-	output int64
+	input   string
+	version byte
+	output  int64
 }
 
 var encodeTests = []encodeTestData{
@@ -28,10 +30,10 @@ var encodeTests = []encodeTestData{
 }
 
 var decodeTests = []decodeTestData{
-	{"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", 1},
-	{"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87K7XCyj5v", 2},
-	{"5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kt87rU1oi9ho", 0xdeadbeef},
-	{"91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgx3cTMqe", 3},
+	{"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", 0x80, 1},
+	{"cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87K7XCyj5v", 0xef, 2},
+	{"5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kt87rU1oi9ho", 0x80, 0xdeadbeef},
+	{"91avARGdfge8E4tZfYLoxeJ5sGBdNJQH4kvjJoQFacbgx3cTMqe", 0xef, 3},
 }
 
 var decodeTestsInvalidCharacter = []string{
@@ -65,7 +67,7 @@ func TestEncodeOk(t *testing.T) {
 
 func TestDecodeOk(t *testing.T) {
 	for _, test := range decodeTests {
-		result, err := base58.Decode(test.input)
+		result, err := base58.Decode(test.input, test.version)
 
 		switch {
 		case err != nil:
@@ -80,7 +82,7 @@ func TestDecodeOk(t *testing.T) {
 
 func TestDecodeInvalidChecksum(t *testing.T) {
 	for _, test := range decodeTestsInvalidChecksum {
-		_, err := base58.Decode(test)
+		_, err := base58.Decode(test, 0x80)
 		if err == nil {
 			t.Errorf("Decode for %s passed, should've failed due to checksum: FAIL\n", test)
 		} else {
@@ -89,9 +91,19 @@ func TestDecodeInvalidChecksum(t *testing.T) {
 	}
 }
 
+func TestDecodeWrongVersion(t *testing.T) {
+	for _, test := range decodeTests {
+		if _, err := base58.Decode(test.input, test.version^0x6f); err == nil {
+			t.Errorf("Decode for %s passed, should've failed due to version: FAIL\n", test.input)
+		} else {
+			t.Logf("Decode for %s failed: %v\n", test.input, err)
+		}
+	}
+}
+
 func TestDecodeInvalidCharacter(t *testing.T) {
 	for _, test := range decodeTestsInvalidCharacter {
-		_, err := base58.Decode(test)
+		_, err := base58.Decode(test, 0x80)
 		if err == nil {
 			t.Errorf("Decode for %s passed, should've failed due to invalid character: FAIL\n", test)
 		} else {
@@ -102,7 +114,7 @@ func TestDecodeInvalidCharacter(t *testing.T) {
 
 func TestDecodeInvalidShortInput(t *testing.T) {
 	for _, test := range decodeTestsInvalidShortInput {
-		_, err := base58.Decode(test)
+		_, err := base58.Decode(test, 0x80)
 		if err == nil {
 			t.Errorf("Decode for %s passed, should've failed due to input too short: FAIL\n", test)
 		} else {
@@ -110,3 +122,81 @@ func TestDecodeInvalidShortInput(t *testing.T) {
 		}
 	}
 }
+
+type bytesTestData struct {
+	input  string
+	output string
+}
+
+var bytesTests = []bytesTestData{
+	{"", ""},
+	{"00", "1"},
+	{"0000", "11"},
+	{"000011", "11J"},
+	{"61", "2g"},
+	{"00000000000000000000", "1111111111"},
+	{"0000287fb4cd", "11233QC4"},
+}
+
+func TestBytes(t *testing.T) {
+	for _, test := range bytesTests {
+		input, _ := hex.DecodeString(test.input)
+		encoded := base58.EncodeBytes(input)
+		decoded, err := base58.DecodeBytes(test.output)
+
+		switch {
+		case encoded != test.output:
+			t.Errorf("EncodeBytes for %s FAILED. Expected %s, got %s\n", test.input, test.output, encoded)
+		case err != nil || hex.EncodeToString(decoded) != test.input:
+			t.Errorf("DecodeBytes for %s FAILED. Expected %s, got %x (%v)\n", test.output, test.input, decoded, err)
+		default:
+			t.Logf("EncodeBytes/DecodeBytes passed: %s, %s\n", test.input, test.output)
+		}
+	}
+}
+
+type checkTestData struct {
+	input   string
+	version string
+	payload string
+}
+
+var checkTests = []checkTestData{
+	{"1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH", "00", "751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"1111111111111111111114oLvT2", "00", "0000000000000000000000000000000000000000"},
+	{"3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN", "05", "bcfeb728b584253d5f3f70bcb780e9ef218a68f4"},
+	{"mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", "6f", "751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn", "80", "000000000000000000000000000000000000000000000000000000000000000101"},
+	{
+		"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+		"0488b21e",
+		"000000000000000000873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d5080339a36013301597daef41fbe593a02cc513d0b55527ec2df1050e2e8ff49c85c2",
+	},
+}
+
+func TestCheck(t *testing.T) {
+	for _, test := range checkTests {
+		version, _ := hex.DecodeString(test.version)
+		payload, _ := hex.DecodeString(test.payload)
+
+		encoded := base58.CheckEncode(version, payload)
+		decodedVersion, decodedPayload, err := base58.CheckDecode(test.input, len(version))
+
+		switch {
+		case encoded != test.input:
+			t.Errorf("CheckEncode for %s %s FAILED. Expected %s, got %s\n", test.version, test.payload, test.input, encoded)
+		case err != nil:
+			t.Errorf("CheckDecode for %s FAILED: %v\n", test.input, err)
+		case hex.EncodeToString(decodedVersion) != test.version || hex.EncodeToString(decodedPayload) != test.payload:
+			t.Errorf("CheckDecode for %s FAILED. Expected %s %s, got %x %x\n", test.input, test.version, test.payload, decodedVersion, decodedPayload)
+		default:
+			t.Logf("CheckEncode/CheckDecode passed: %s\n", test.input)
+		}
+	}
+
+	for _, test := range append(decodeTestsInvalidChecksum, decodeTestsInvalidCharacter...) {
+		if _, _, err := base58.CheckDecode(test, 1); err == nil {
+			t.Errorf("CheckDecode for %s passed, should've failed: FAIL\n", test)
+		}
+	}
+}
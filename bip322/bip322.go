@@ -0,0 +1,163 @@
+package bip322
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/tx"
+)
+
+// MessageHash returns the BIP322 tagged hash of a message
+func MessageHash(message string) []byte {
+	return crypto.TaggedHash("BIP0322-signed-message", []byte(message))
+}
+
+// ToSpend returns the virtual to_spend transaction of a message for a scriptPubKey
+func ToSpend(scriptPubKey []byte, message string) *tx.Tx {
+	scriptSig := append([]byte{script.Op0}, script.PushData(MessageHash(message))...)
+	return &tx.Tx{
+		Version: 0,
+		Inputs: []tx.Input{{
+			PrevHash:  make([]byte, 32),
+			PrevIndex: 0xffffffff,
+			ScriptSig: scriptSig,
+			Sequence:  0,
+		}},
+		Outputs: []tx.Output{{Value: 0, Script: scriptPubKey}},
+	}
+}
+
+// ToSign returns the virtual to_sign transaction spending to_spend with a witness
+func ToSign(toSpend *tx.Tx, witness [][]byte) *tx.Tx {
+	return &tx.Tx{
+		Version: 0,
+		Inputs: []tx.Input{{
+			PrevHash:  toSpend.Hash(),
+			PrevIndex: 0,
+			Sequence:  0,
+			Witness:   witness,
+		}},
+		Outputs: []tx.Output{{Value: 0, Script: []byte{0x6a}}},
+	}
+}
+
+// Sign returns the base64 BIP322 simple signature of a message for the P2WPKH or
+// P2TR (key-path) scriptPubKey of the key.
+func Sign(priv *keys.PrivateKey, scriptPubKey []byte, message string) (string, error) {
+	toSpend := ToSpend(scriptPubKey, message)
+	toSign := ToSign(toSpend, nil)
+	prevouts := []tx.Output{toSpend.Outputs[0]}
+
+	var witness [][]byte
+	switch hexScript := hex.EncodeToString(scriptPubKey); hexScript {
+	case priv.ToScriptSegwit():
+		scriptCode, _ := hex.DecodeString(priv.ToScriptLegacy())
+		sighash, err := toSign.SigHashSegwitV0(0, scriptCode, 0, tx.SigHashAll)
+		if err != nil {
+			return "", err
+		}
+
+		signature, err := priv.SignECDSA(sighash)
+		if err != nil {
+			return "", err
+		}
+		witness = [][]byte{append(signature, tx.SigHashAll), priv.PublicKey()}
+
+	case priv.ToScriptTaproot():
+		sighash, err := toSign.SigHashTaproot(0, prevouts, tx.SigHashDefault)
+		if err != nil {
+			return "", err
+		}
+
+		signature, err := priv.SignTaproot(sighash, nil)
+		if err != nil {
+			return "", err
+		}
+		witness = [][]byte{signature}
+
+	default:
+		return "", fmt.Errorf("BIP322 signing supports the P2WPKH and P2TR scripts of the key, got %s", hexScript)
+	}
+
+	return base64.StdEncoding.EncodeToString(tx.SerializeWitness(witness)), nil
+}
+
+// Verify checks a base64 BIP322 simple signature of a message against a P2WPKH or P2TR address
+func Verify(addr, message, signature string) error {
+	decoded, err := address.Decode(addr)
+	if err != nil {
+		return err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	witness, err := tx.DeserializeWitness(data)
+	if err != nil {
+		return err
+	}
+
+	toSpend := ToSpend(decoded.Script, message)
+	toSign := ToSign(toSpend, witness)
+
+	switch decoded.Type {
+	case address.TypeP2WPKH:
+		return verifyP2WPKH(toSign, decoded.Program, witness)
+	case address.TypeP2TR:
+		return verifyP2TR(toSign, []tx.Output{toSpend.Outputs[0]}, decoded.Program, witness)
+	default:
+		return fmt.Errorf("BIP322 simple verification supports P2WPKH and P2TR addresses, got %s", decoded.Type)
+	}
+}
+
+func verifyP2WPKH(toSign *tx.Tx, program []byte, witness [][]byte) error {
+	if len(witness) != 2 || len(witness[0]) == 0 {
+		return errors.New("invalid P2WPKH witness: expected signature and public key")
+	}
+
+	sig, pubkey := witness[0], witness[1]
+	if len(pubkey) != 33 || !bytes.Equal(crypto.Hash160(pubkey), program) {
+		return errors.New("public key does not match the address")
+	}
+
+	hashType := sig[len(sig)-1]
+	scriptCode := script.P2PKH(program)
+	sighash, err := toSign.SigHashSegwitV0(0, scriptCode, 0, hashType)
+	if err != nil {
+		return err
+	}
+
+	return keys.VerifyECDSA(pubkey, sighash, sig[:len(sig)-1])
+}
+
+func verifyP2TR(toSign *tx.Tx, prevouts []tx.Output, outputKey []byte, witness [][]byte) error {
+	if len(witness) != 1 {
+		return errors.New("invalid P2TR witness: only key-path signatures are supported")
+	}
+
+	sig, hashType := witness[0], tx.SigHashDefault
+	switch len(sig) {
+	case 64:
+	case 65:
+		if sig[64] == tx.SigHashDefault {
+			return errors.New("invalid signature: explicit SIGHASH_DEFAULT")
+		}
+		sig, hashType = sig[:64], sig[64]
+	default:
+		return fmt.Errorf("invalid signature length: %d", len(sig))
+	}
+
+	sighash, err := toSign.SigHashTaproot(0, prevouts, hashType)
+	if err != nil {
+		return err
+	}
+	return keys.VerifySchnorr(outputKey, sighash, sig)
+}
@@ -0,0 +1,96 @@
+package bip322_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bip322"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/keys"
+)
+
+const wif = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+
+type hashTestData struct {
+	message     string
+	messageHash string
+	toSpend     string
+	toSign      string
+}
+
+// BIP322 test vectors
+var hashTests = []hashTestData{
+	{"", "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1", "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7", "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6"},
+	{"Hello World", "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a", "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b", "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf"},
+}
+
+type verifyTestData struct {
+	address   string
+	message   string
+	signature string
+	valid     bool
+}
+
+var verifyTests = []verifyTestData{
+	{"bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l", "Hello World", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=", true},
+	{"bc1q9vza2e8x573nczrlzms0wvx3gsqjx7vavgkx0l", "Hello World!", "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=", false},
+	{"bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3", "Hello World", "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==", true},
+	{"bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3", "", "AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==", false},
+}
+
+func TestHashes(t *testing.T) {
+	script, _ := hex.DecodeString("00142b05d564e6a7a33c087f16e0f730d1440123799d")
+	for _, test := range hashTests {
+		toSpend := bip322.ToSpend(script, test.message)
+		messageHash := hex.EncodeToString(bip322.MessageHash(test.message))
+		toSpendID := hex.EncodeToString(toSpend.TxID())
+		toSignID := hex.EncodeToString(bip322.ToSign(toSpend, nil).TxID())
+
+		if messageHash != test.messageHash || toSpendID != test.toSpend || toSignID != test.toSign {
+			t.Errorf("Hashes for %q FAILED. Expected %s %s %s, got %s %s %s\n", test.message,
+				test.messageHash, test.toSpend, test.toSign, messageHash, toSpendID, toSignID)
+		} else {
+			t.Logf("Hashes passed: %q, %s\n", test.message, messageHash)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	for _, test := range verifyTests {
+		err := bip322.Verify(test.address, test.message, test.signature)
+		if (err == nil) != test.valid {
+			t.Errorf("Verify for %s %q FAILED. Expected valid %t, got %v\n", test.address, test.message, test.valid, err)
+		} else {
+			t.Logf("Verify passed: %s %q, %t\n", test.address, test.message, test.valid)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	number, _ := base58.Decode(wif, chaincfg.MainNet.PrivateKeyID)
+	privateKey := keys.FromBigInt(number, chaincfg.MainNet)
+
+	for _, test := range []struct{ address, script string }{
+		{privateKey.ToAddressSegWit(), privateKey.ToScriptSegwit()},
+		{privateKey.ToAddressTaproot(), privateKey.ToScriptTaproot()},
+	} {
+		script, _ := hex.DecodeString(test.script)
+		signature, err := bip322.Sign(&privateKey, script, "Hello World")
+		if err != nil {
+			t.Errorf("Sign for %s FAILED: %v\n", test.address, err)
+			continue
+		}
+
+		if err := bip322.Verify(test.address, "Hello World", signature); err != nil {
+			t.Errorf("Verify for %s FAILED: %v\n", test.address, err)
+		} else {
+			t.Logf("Sign passed: %s, %s\n", test.address, signature)
+		}
+	}
+
+	script, _ := hex.DecodeString(privateKey.ToScriptLegacy())
+	if _, err := bip322.Sign(&privateKey, script, "Hello World"); err == nil {
+		t.Errorf("Sign for a P2PKH script passed, should've failed: FAIL\n")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ottosch/pick-private/bip38"
+)
+
+func runBip38(args []string) {
+	flags := flag.NewFlagSet("bip38", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s bip38 [options] intermediate | encrypt intermediate-code | confirm confirmation-code\n", os.Args[0])
+		fmt.Println("\nEC-multiplied BIP38: the passphrase is prompted for, or read from stdin.")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s bip38 intermediate -lot 263183 -sequence 1\n", os.Args[0])
+		fmt.Printf("  %s bip38 encrypt passphraseaB8feaLQDENqCgr4gKZpmf4VoaT6qdjJNJiv7fsKvjqavcJxvuR1hy25aTu5sX\n", os.Args[0])
+		fmt.Printf("  %s bip38 confirm cfrm38V8aXBn7JWA1ESmFMUn6erxeBGZGAxJPY4e36S9QWkzZKtaVqLNMgnifETYw7BPwWC9aPD\n", os.Args[0])
+	}
+
+	var lot, sequence int
+	var uncompressed bool
+	var coinFlag, networkFlag string
+	flags.IntVar(&lot, "lot", -1, "lot number (0-1048575) encoded in the intermediate code")
+	flags.IntVar(&sequence, "sequence", 0, "sequence number (0-4095) encoded in the intermediate code, used with -lot")
+	flags.BoolVar(&uncompressed, "uncompressed", false, "encrypt a key with an uncompressed public key")
+	flags.StringVar(&coinFlag, "coin", "btc", coinUsage)
+	flags.StringVar(&networkFlag, "network", "mainnet", networkUsage)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	params := lookupNetwork(coinFlag, networkFlag)
+	switch {
+	case positional[0] == "intermediate" && len(positional) == 1:
+		if lot < 0 && sequence != 0 {
+			fmt.Fprintln(os.Stderr, "-sequence requires -lot")
+			os.Exit(1)
+		}
+		// checked before the uint32 conversion, which would wrap large values
+		if lot < -1 || lot > 1048575 || sequence < 0 || sequence > 4095 {
+			fmt.Fprintln(os.Stderr, "-lot must be 0 to 1048575 and -sequence 0 to 4095")
+			os.Exit(1)
+		}
+
+		code, err := bip38.NewIntermediateCode(readPassphrase("BIP38 passphrase: "), lot >= 0, uint32(lot), uint32(sequence))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Println("[BIP38 intermediate code]")
+		fmt.Println(code)
+
+	case positional[0] == "encrypt" && len(positional) == 2:
+		encrypted, confirmation, address, err := bip38.EncryptFromIntermediate(positional[1], !uncompressed, params)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Println("[BIP38 EC-multiplied key]")
+		fmt.Printf("     Address: %s\n", address)
+		fmt.Printf("   Encrypted: %s\n", encrypted)
+		fmt.Printf("Confirmation: %s\n", confirmation)
+
+	case positional[0] == "confirm" && len(positional) == 2:
+		address, err := bip38.VerifyConfirmation(positional[1], readPassphrase("BIP38 passphrase: "), params)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Println("[BIP38 confirmation]")
+		fmt.Printf("Address: %s\n", address)
+
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+}
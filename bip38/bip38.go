@@ -0,0 +1,159 @@
+package bip38
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for the non-EC-multiply method
+const (
+	scryptN      = 16384
+	scryptR      = 8
+	scryptP      = 8
+	scryptKeyLen = 64
+)
+
+var (
+	prefixNonEC = []byte{0x01, 0x42}
+	prefixEC    = []byte{0x01, 0x43}
+)
+
+const (
+	flagNonEC      byte = 0xC0
+	flagCompressed byte = 0x20
+)
+
+// Encrypt encrypts a private key with a passphrase (BIP38, non-EC-multiply).
+// The passphrase is used as given; BIP38 expects it to be NFC normalized.
+func Encrypt(priv *keys.PrivateKey, passphrase string, compressed bool) (string, error) {
+	if err := keys.CheckPrivateKey(priv.PrivateKey()); err != nil {
+		return "", err
+	}
+
+	address := priv.ToAddressLegacyUncompressed()
+	flag := flagNonEC
+	if compressed {
+		address = priv.ToAddressLegacy()
+		flag |= flagCompressed
+	}
+
+	addressHash := crypto.Hash256([]byte(address))[:4]
+	derived, err := scrypt.Key([]byte(passphrase), addressHash, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	secret := make([]byte, 32)
+	priv.PrivateKey().FillBytes(secret)
+
+	encrypted, err := aesEncrypt(xor(secret, derived[:32]), derived[32:])
+	if err != nil {
+		return "", err
+	}
+
+	payload := append([]byte{flag}, addressHash...)
+	payload = append(payload, encrypted...)
+	return base58.CheckEncode(prefixNonEC, payload), nil
+}
+
+// Decrypt decrypts a BIP38 key (6P...) with a passphrase, returning the private key
+// and whether it was encrypted for its compressed public key.
+func Decrypt(encrypted, passphrase string, params *chaincfg.Params) (keys.PrivateKey, bool, error) {
+	prefix, payload, err := base58.CheckDecode(encrypted, 2)
+	if err != nil {
+		return keys.PrivateKey{}, false, fmt.Errorf("invalid BIP38 key: %v", err)
+	}
+	if len(payload) != 37 {
+		return keys.PrivateKey{}, false, fmt.Errorf("invalid BIP38 key length: %d", len(payload)+2)
+	}
+
+	switch {
+	case bytes.Equal(prefix, prefixNonEC):
+		return decryptNonEC(payload, passphrase, params)
+	case bytes.Equal(prefix, prefixEC):
+		return decryptEC(payload, passphrase, params)
+	default:
+		return keys.PrivateKey{}, false, fmt.Errorf("invalid BIP38 prefix: %x", prefix)
+	}
+}
+
+func decryptNonEC(payload []byte, passphrase string, params *chaincfg.Params) (keys.PrivateKey, bool, error) {
+	flag, addressHash := payload[0], payload[1:5]
+	if flag&^flagCompressed != flagNonEC {
+		return keys.PrivateKey{}, false, fmt.Errorf("invalid BIP38 flag byte: %02x", flag)
+	}
+	compressed := flag&flagCompressed != 0
+
+	derived, err := scrypt.Key([]byte(passphrase), addressHash, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+
+	decrypted, err := aesDecrypt(payload[5:], derived[32:])
+	if err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+
+	priv := keys.FromBigInt(new(big.Int).SetBytes(xor(decrypted, derived[:32])), params)
+	if err := checkAddressHash(&priv, compressed, addressHash); err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+	return priv, compressed, nil
+}
+
+func checkAddressHash(priv *keys.PrivateKey, compressed bool, addressHash []byte) error {
+	address := priv.ToAddressLegacyUncompressed()
+	if compressed {
+		address = priv.ToAddressLegacy()
+	}
+
+	if !bytes.Equal(crypto.Hash256([]byte(address))[:4], addressHash) {
+		return errors.New("wrong BIP38 passphrase (address hash mismatch)")
+	}
+	return nil
+}
+
+// aesEncrypt encrypts 32 bytes as two AES-256 ECB blocks
+func aesEncrypt(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Encrypt(out[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return out, nil
+}
+
+// aesDecrypt decrypts 32 bytes of two AES-256 ECB blocks
+func aesDecrypt(data, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(out[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return out, nil
+}
+
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i]
+	}
+	return out
+}
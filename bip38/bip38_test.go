@@ -0,0 +1,175 @@
+package bip38_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bip38"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/keys"
+)
+
+type testData struct {
+	passphrase string
+	encrypted  string
+	privateKey string
+	compressed bool
+}
+
+// BIP38 test vectors (no EC multiply)
+var tests = []testData{
+	{"TestingOneTwoThree", "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg", "cbf4b9f70470856bb4f40f80b87edb90865997ffee6df315ab166d713af433a5", false},
+	{"Satoshi", "6PRNFFkZc2NZ6dJqFfhRoFNMR9Lnyj7dYGrzdgXXVMXcxoKTePPX1dWByq", "09c2686880095b1a4c249ee3ac4eea8a014f11e6f986d0b5025ac1f39afbd9ae", false},
+	{"TestingOneTwoThree", "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo", "cbf4b9f70470856bb4f40f80b87edb90865997ffee6df315ab166d713af433a5", true},
+	{"Satoshi", "6PYLtMnXvfG3oJde97zRyLYFZCYizPU5T3LwgdYJz1fRhh16bU7u6PPmY7", "09c2686880095b1a4c249ee3ac4eea8a014f11e6f986d0b5025ac1f39afbd9ae", true},
+}
+
+func TestEncrypt(t *testing.T) {
+	for _, test := range tests {
+		number, _ := new(big.Int).SetString(test.privateKey, 16)
+		privateKey := keys.FromBigInt(number, chaincfg.MainNet)
+
+		result, err := bip38.Encrypt(&privateKey, test.passphrase, test.compressed)
+		switch {
+		case err != nil:
+			t.Errorf("Encrypt for %s FAILED: %v\n", test.privateKey, err)
+		case result != test.encrypted:
+			t.Errorf("Encrypt for %s FAILED. Expected %s, got %s\n", test.privateKey, test.encrypted, result)
+		default:
+			t.Logf("Encrypt passed: %s, %s\n", test.privateKey, test.encrypted)
+		}
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	for _, test := range tests {
+		privateKey, compressed, err := bip38.Decrypt(test.encrypted, test.passphrase, chaincfg.MainNet)
+		result := fmt.Sprintf("%064x", privateKey.PrivateKey())
+
+		switch {
+		case err != nil:
+			t.Errorf("Decrypt for %s FAILED: %v\n", test.encrypted, err)
+		case result != test.privateKey || compressed != test.compressed:
+			t.Errorf("Decrypt for %s FAILED. Expected %s (%t), got %s (%t)\n", test.encrypted, test.privateKey, test.compressed, result, compressed)
+		default:
+			t.Logf("Decrypt passed: %s, %s\n", test.encrypted, test.privateKey)
+		}
+	}
+}
+
+func TestDecryptWrongPassphrase(t *testing.T) {
+	if _, _, err := bip38.Decrypt(tests[0].encrypted, "wrong", chaincfg.MainNet); err == nil {
+		t.Errorf("Decrypt with a wrong passphrase passed, should've failed: FAIL\n")
+	} else {
+		t.Logf("Decrypt with a wrong passphrase failed: %v\n", err)
+	}
+}
+
+type testDataEC struct {
+	passphrase   string
+	intermediate string
+	encrypted    string
+	privateKey   string
+	confirmation string
+	address      string
+}
+
+// BIP38 test vectors (EC multiply)
+var testsEC = []testDataEC{
+	{"TestingOneTwoThree", "passphrasepxFy57B9v8HtUsszJYKReoNDV6VHjUSGt8EVJmux9n1J3Ltf1gRxyDGXqnf9qm", "6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX", "a43a940577f4e97f5c4d39eb14ff083a98187c64ea7c99ef7ce460833959a519", "", "1PE6TQi6HTVNz5DLwB1LcpMBALubfuN2z2"},
+	{"Satoshi", "passphraseoRDGAXTWzbp72eVbtUDdn1rwpgPUGjNZEc6CGBo8i5EC1FPW8wcnLdq4ThKzAS", "6PfLGnQs6VZnrNpmVKfjotbnQuaJK4KZoPFrAjx1JMJUa1Ft8gnf5WxfKd", "c2c8036df268f498099350718c4a3ef3984d2be84618c2650f5171dcc5eb660a", "", "1CqzrtZC6mXSAhoxtFwVjz8LtwLJjDYU3V"},
+	{"MOLON LABE", "passphraseaB8feaLQDENqCgr4gKZpmf4VoaT6qdjJNJiv7fsKvjqavcJxvuR1hy25aTu5sX", "6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j", "44ea95afbf138356a05ea32110dfd627232d0f2991ad221187be356f19fa8190", "cfrm38V8aXBn7JWA1ESmFMUn6erxeBGZGAxJPY4e36S9QWkzZKtaVqLNMgnifETYw7BPwWC9aPD", "1Jscj8ALrYu2y9TD8NrpvDBugPedmbj4Yh"},
+}
+
+func TestDecryptEC(t *testing.T) {
+	for _, test := range testsEC {
+		privateKey, compressed, err := bip38.Decrypt(test.encrypted, test.passphrase, chaincfg.MainNet)
+		result := fmt.Sprintf("%064x", privateKey.PrivateKey())
+
+		switch {
+		case err != nil:
+			t.Errorf("Decrypt for %s FAILED: %v\n", test.encrypted, err)
+		case result != test.privateKey || compressed || privateKey.ToAddressLegacyUncompressed() != test.address:
+			t.Errorf("Decrypt for %s FAILED. Expected %s, got %s\n", test.encrypted, test.privateKey, result)
+		default:
+			t.Logf("Decrypt passed: %s, %s\n", test.encrypted, test.privateKey)
+		}
+	}
+}
+
+func TestIntermediateCode(t *testing.T) {
+	for _, test := range testsEC {
+		magic, payload, err := base58.CheckDecode(test.intermediate, 8)
+		if err != nil {
+			t.Fatalf("CheckDecode for %s FAILED: %v\n", test.intermediate, err)
+		}
+
+		result, err := bip38.IntermediateCodeFromEntropy(test.passphrase, payload[:8], magic[7] == 0x51)
+		switch {
+		case err != nil:
+			t.Errorf("IntermediateCodeFromEntropy for %s FAILED: %v\n", test.passphrase, err)
+		case result != test.intermediate:
+			t.Errorf("IntermediateCodeFromEntropy for %s FAILED. Expected %s, got %s\n", test.passphrase, test.intermediate, result)
+		default:
+			t.Logf("IntermediateCodeFromEntropy passed: %s, %s\n", test.passphrase, test.intermediate)
+		}
+	}
+}
+
+func TestVerifyConfirmation(t *testing.T) {
+	for _, test := range testsEC {
+		if test.confirmation == "" {
+			continue
+		}
+
+		address, err := bip38.VerifyConfirmation(test.confirmation, test.passphrase, chaincfg.MainNet)
+		switch {
+		case err != nil:
+			t.Errorf("VerifyConfirmation for %s FAILED: %v\n", test.confirmation, err)
+		case address != test.address:
+			t.Errorf("VerifyConfirmation for %s FAILED. Expected %s, got %s\n", test.confirmation, test.address, address)
+		default:
+			t.Logf("VerifyConfirmation passed: %s, %s\n", test.confirmation, address)
+		}
+
+		if _, err := bip38.VerifyConfirmation(test.confirmation, "wrong", chaincfg.MainNet); err == nil {
+			t.Errorf("VerifyConfirmation with a wrong passphrase passed, should've failed: FAIL\n")
+		}
+	}
+}
+
+func TestEncryptFromIntermediate(t *testing.T) {
+	for _, lotSequence := range []bool{false, true} {
+		for _, compressed := range []bool{false, true} {
+			code, err := bip38.NewIntermediateCode("pick-private", lotSequence, 263183, 1)
+			if err != nil {
+				t.Fatalf("NewIntermediateCode FAILED: %v\n", err)
+			}
+
+			encrypted, confirmation, address, err := bip38.EncryptFromIntermediate(code, compressed, chaincfg.MainNet)
+			if err != nil {
+				t.Fatalf("EncryptFromIntermediate for %s FAILED: %v\n", code, err)
+			}
+
+			privateKey, resultCompressed, err := bip38.Decrypt(encrypted, "pick-private", chaincfg.MainNet)
+			resultAddress := privateKey.ToAddressLegacyUncompressed()
+			if compressed {
+				resultAddress = privateKey.ToAddressLegacy()
+			}
+			confirmed, confirmErr := bip38.VerifyConfirmation(confirmation, "pick-private", chaincfg.MainNet)
+
+			switch {
+			case err != nil:
+				t.Errorf("Decrypt for %s FAILED: %v\n", encrypted, err)
+			case confirmErr != nil:
+				t.Errorf("VerifyConfirmation for %s FAILED: %v\n", confirmation, confirmErr)
+			case resultCompressed != compressed || resultAddress != address || confirmed != address:
+				t.Errorf("EncryptFromIntermediate for %s FAILED. Expected %s, got %s and %s\n", code, address, resultAddress, confirmed)
+			default:
+				t.Logf("EncryptFromIntermediate passed: %s, %s, %s\n", encrypted, confirmation, address)
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package bip38
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt parameters for deriving the passpoint-based key (EC-multiply method)
+const (
+	scryptPassN = 1024
+	scryptPassR = 1
+	scryptPassP = 1
+)
+
+const (
+	flagLotSequence byte = 0x04
+
+	maxLot      = 1<<20 - 1
+	maxSequence = 1<<12 - 1
+)
+
+var (
+	magicIntermediate            = []byte{0x2C, 0xE9, 0xB3, 0xE1, 0xFF, 0x39, 0xE2, 0x53}
+	magicIntermediateLotSequence = []byte{0x2C, 0xE9, 0xB3, 0xE1, 0xFF, 0x39, 0xE2, 0x51}
+	prefixConfirmation           = []byte{0x64, 0x3B, 0xF6, 0xA8, 0x9A}
+)
+
+// NewIntermediateCode returns a passphrase intermediate code (passphrase...) with a random owner salt.
+// With lotSequence, the lot (< 2^20) and sequence (< 4096) numbers are encoded in the owner entropy.
+func NewIntermediateCode(passphrase string, lotSequence bool, lot, sequence uint32) (string, error) {
+	ownerEntropy := make([]byte, 8)
+	if _, err := rand.Read(ownerEntropy); err != nil {
+		return "", err
+	}
+
+	if lotSequence {
+		if lot > maxLot || sequence > maxSequence {
+			return "", fmt.Errorf("lot must be <= %d and sequence <= %d", maxLot, maxSequence)
+		}
+		binary.BigEndian.PutUint32(ownerEntropy[4:], lot<<12|sequence)
+	}
+
+	return IntermediateCodeFromEntropy(passphrase, ownerEntropy, lotSequence)
+}
+
+// IntermediateCodeFromEntropy returns the passphrase intermediate code for a given 8-byte owner entropy
+func IntermediateCodeFromEntropy(passphrase string, ownerEntropy []byte, lotSequence bool) (string, error) {
+	if len(ownerEntropy) != 8 {
+		return "", fmt.Errorf("invalid owner entropy length: %d", len(ownerEntropy))
+	}
+
+	_, passpoint, err := passFactor(passphrase, ownerEntropy, lotSequence)
+	if err != nil {
+		return "", err
+	}
+
+	magic := magicIntermediate
+	if lotSequence {
+		magic = magicIntermediateLotSequence
+	}
+	return base58.CheckEncode(magic, append(append([]byte{}, ownerEntropy...), passpoint...)), nil
+}
+
+// EncryptFromIntermediate creates a new random encrypted key from an intermediate code, without
+// knowing the passphrase. It returns the encrypted key (6P...), its confirmation code (cfrm38...)
+// and the address of the key.
+func EncryptFromIntermediate(code string, compressed bool, params *chaincfg.Params) (string, string, string, error) {
+	magic, payload, err := base58.CheckDecode(code, 8)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid intermediate code: %v", err)
+	}
+
+	var flag byte
+	switch {
+	case len(payload) != 41:
+		return "", "", "", fmt.Errorf("invalid intermediate code length: %d", len(payload)+8)
+	case bytes.Equal(magic, magicIntermediateLotSequence):
+		flag |= flagLotSequence
+	case !bytes.Equal(magic, magicIntermediate):
+		return "", "", "", fmt.Errorf("invalid intermediate code magic: %x", magic)
+	}
+	if compressed {
+		flag |= flagCompressed
+	}
+
+	ownerEntropy, passpoint := payload[:8], payload[8:]
+	passX, passY, err := parsePoint(passpoint)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	seedB := make([]byte, 24)
+	if _, err := rand.Read(seedB); err != nil {
+		return "", "", "", err
+	}
+	factorB := crypto.Hash256(seedB)
+
+	curve := secp256k1.S256()
+	x, y := curve.ScalarMult(passX, passY, factorB)
+	address := pointAddress(x, y, compressed, params)
+	addressHash := crypto.Hash256([]byte(address))[:4]
+
+	derived, err := scrypt.Key(passpoint, append(append([]byte{}, addressHash...), ownerEntropy...),
+		scryptPassN, scryptPassR, scryptPassP, scryptKeyLen)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	part1, _ := aesEncrypt(xor(seedB[:16], derived[:16]), derived[32:])
+	part2, _ := aesEncrypt(xor(append(append([]byte{}, part1[8:16]...), seedB[16:24]...), derived[16:32]), derived[32:])
+
+	header := append([]byte{flag}, addressHash...)
+	header = append(header, ownerEntropy...)
+
+	encrypted := append(append([]byte{}, header...), part1[:8]...)
+	encrypted = append(encrypted, part2...)
+
+	bx, by := curve.ScalarBaseMult(factorB)
+	pointB := secp256k1.NewPublicKey(bx, by).SerializeCompressed()
+	pointBX, _ := aesEncrypt(xor(pointB[1:], derived[:32]), derived[32:])
+	encryptedPointB := append([]byte{pointB[0] ^ derived[63]&0x01}, pointBX...)
+
+	confirmation := append(append([]byte{}, header...), encryptedPointB...)
+	return base58.CheckEncode(prefixEC, encrypted), base58.CheckEncode(prefixConfirmation, confirmation), address, nil
+}
+
+// VerifyConfirmation checks a confirmation code (cfrm38...) against a passphrase,
+// returning the address of the encrypted key it confirms.
+func VerifyConfirmation(code, passphrase string, params *chaincfg.Params) (string, error) {
+	prefix, payload, err := base58.CheckDecode(code, 5)
+	if err != nil {
+		return "", fmt.Errorf("invalid confirmation code: %v", err)
+	}
+	if !bytes.Equal(prefix, prefixConfirmation) || len(payload) != 46 {
+		return "", errors.New("invalid confirmation code")
+	}
+
+	flag, addressHash, ownerEntropy := payload[0], payload[1:5], payload[5:13]
+	passfactor, passpoint, err := passFactor(passphrase, ownerEntropy, flag&flagLotSequence != 0)
+	if err != nil {
+		return "", err
+	}
+
+	derived, err := scrypt.Key(passpoint, payload[1:13], scryptPassN, scryptPassR, scryptPassP, scryptKeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	pointBX, _ := aesDecrypt(payload[14:46], derived[32:])
+	pointB := append([]byte{payload[13] ^ derived[63]&0x01}, xor(pointBX, derived[:32])...)
+	bx, by, err := parsePoint(pointB)
+	if err != nil {
+		return "", errors.New("wrong BIP38 passphrase (invalid confirmation point)")
+	}
+
+	x, y := secp256k1.S256().ScalarMult(bx, by, passfactor)
+	address := pointAddress(x, y, flag&flagCompressed != 0, params)
+	if !bytes.Equal(crypto.Hash256([]byte(address))[:4], addressHash) {
+		return "", errors.New("wrong BIP38 passphrase (address hash mismatch)")
+	}
+	return address, nil
+}
+
+func decryptEC(payload []byte, passphrase string, params *chaincfg.Params) (keys.PrivateKey, bool, error) {
+	flag, addressHash, ownerEntropy := payload[0], payload[1:5], payload[5:13]
+	if flag&^(flagCompressed|flagLotSequence) != 0 {
+		return keys.PrivateKey{}, false, fmt.Errorf("invalid BIP38 flag byte: %02x", flag)
+	}
+	compressed := flag&flagCompressed != 0
+
+	passfactor, passpoint, err := passFactor(passphrase, ownerEntropy, flag&flagLotSequence != 0)
+	if err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+
+	derived, err := scrypt.Key(passpoint, payload[1:13], scryptPassN, scryptPassR, scryptPassP, scryptKeyLen)
+	if err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+
+	decrypted2, _ := aesDecrypt(payload[21:37], derived[32:])
+	decrypted2 = xor(decrypted2, derived[16:32])
+
+	part1 := append(append([]byte{}, payload[13:21]...), decrypted2[:8]...)
+	decrypted1, _ := aesDecrypt(part1, derived[32:])
+	seedB := append(xor(decrypted1, derived[:16]), decrypted2[8:16]...)
+
+	n := secp256k1.S256().Params().N
+	number := new(big.Int).SetBytes(crypto.Hash256(seedB))
+	number.Mul(number, new(big.Int).SetBytes(passfactor))
+	number.Mod(number, n)
+
+	priv := keys.FromBigInt(number, params)
+	if err := checkAddressHash(&priv, compressed, addressHash); err != nil {
+		return keys.PrivateKey{}, false, err
+	}
+	return priv, compressed, nil
+}
+
+// passFactor derives the passfactor and the compressed passpoint from a passphrase and owner entropy
+func passFactor(passphrase string, ownerEntropy []byte, lotSequence bool) ([]byte, []byte, error) {
+	ownerSalt := ownerEntropy
+	if lotSequence {
+		ownerSalt = ownerEntropy[:4]
+	}
+
+	prefactor, err := scrypt.Key([]byte(passphrase), ownerSalt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	passfactor := prefactor
+	if lotSequence {
+		passfactor = crypto.Hash256(append(prefactor, ownerEntropy...))
+	}
+
+	n := secp256k1.S256().Params().N
+	if number := new(big.Int).SetBytes(passfactor); number.Sign() == 0 || number.Cmp(n) >= 0 {
+		return nil, nil, errors.New("invalid passfactor, use another owner salt")
+	}
+
+	x, y := secp256k1.S256().ScalarBaseMult(passfactor)
+	return passfactor, secp256k1.NewPublicKey(x, y).SerializeCompressed(), nil
+}
+
+func parsePoint(point []byte) (*big.Int, *big.Int, error) {
+	pubkey, err := secp256k1.ParsePubKey(point)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid point: %v", err)
+	}
+	return pubkey.X, pubkey.Y, nil
+}
+
+func pointAddress(x, y *big.Int, compressed bool, params *chaincfg.Params) string {
+	pubkey := secp256k1.NewPublicKey(x, y).SerializeUncompressed()
+	if compressed {
+		pubkey = secp256k1.NewPublicKey(x, y).SerializeCompressed()
+	}
+	return base58.CheckEncode([]byte{params.PubKeyHashAddrID}, crypto.Hash160(pubkey))
+}
@@ -0,0 +1,154 @@
+package cashaddr
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Address types (high bits of the version byte)
+const (
+	TypeP2PKH byte = 0
+	TypeP2SH  byte = 1
+)
+
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var generator = []uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+
+// hash sizes in bits, indexed by the size bits of the version byte
+var hashSizes = []int{160, 192, 224, 256, 320, 384, 448, 512}
+
+func polymod(values []byte) uint64 {
+	chk := uint64(1)
+	for _, v := range values {
+		top := chk >> 35
+		chk = (chk&0x07ffffffff)<<5 ^ uint64(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk ^ 1
+}
+
+func prefixExpand(prefix string) []byte {
+	ret := make([]byte, 0, len(prefix)+1)
+	for _, c := range prefix {
+		ret = append(ret, byte(c)&31)
+	}
+	return append(ret, 0)
+}
+
+func createChecksum(prefix string, data []byte) []byte {
+	values := append(prefixExpand(prefix), data...)
+	mod := polymod(append(values, make([]byte, 8)...))
+
+	ret := make([]byte, 8)
+	for p := range ret {
+		ret[p] = byte(mod>>uint(5*(7-p))) & 31
+	}
+	return ret
+}
+
+// Encode encodes a hash of the given type as a CashAddr, like bitcoincash:qp...
+func Encode(prefix string, addrType byte, hash []byte) (string, error) {
+	sizeCode := -1
+	for i, size := range hashSizes {
+		if size == len(hash)*8 {
+			sizeCode = i
+		}
+	}
+	if sizeCode < 0 {
+		return "", fmt.Errorf("invalid hash length: %d", len(hash))
+	}
+	if addrType > 15 {
+		return "", fmt.Errorf("invalid address type: %d", addrType)
+	}
+
+	prefix = strings.ToLower(prefix)
+	payload := append([]byte{addrType<<3 | byte(sizeCode)}, hash...)
+	data, err := convertbits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	var ret bytes.Buffer
+	ret.WriteString(prefix)
+	ret.WriteString(":")
+	for _, d := range append(data, createChecksum(prefix, data)...) {
+		ret.WriteByte(charset[d])
+	}
+	return ret.String(), nil
+}
+
+// Decode decodes a CashAddr into its prefix, type and hash.
+// Addresses without a prefix are checked against defaultPrefix.
+func Decode(addr, defaultPrefix string) (string, byte, []byte, error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return "", 0, nil, fmt.Errorf("mixed case")
+	}
+	addr = strings.ToLower(addr)
+
+	prefix, encoded := strings.ToLower(defaultPrefix), addr
+	if pos := strings.LastIndex(addr, ":"); pos >= 0 {
+		prefix, encoded = addr[:pos], addr[pos+1:]
+	}
+	if prefix == "" {
+		return "", 0, nil, fmt.Errorf("missing prefix")
+	}
+
+	data := make([]byte, 0, len(encoded))
+	for p := 0; p < len(encoded); p++ {
+		d := strings.IndexByte(charset, encoded[p])
+		if d == -1 {
+			return "", 0, nil, fmt.Errorf("invalid character : %c", encoded[p])
+		}
+		data = append(data, byte(d))
+	}
+
+	if len(data) < 8 || polymod(append(prefixExpand(prefix), data...)) != 0 {
+		return "", 0, nil, fmt.Errorf("invalid checksum")
+	}
+
+	payload, err := convertbits(data[:len(data)-8], 5, 8, false)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if len(payload) < 1 || payload[0]&0x80 != 0 {
+		return "", 0, nil, fmt.Errorf("invalid version byte")
+	}
+
+	version, hash := payload[0], payload[1:]
+	if hashSizes[version&0x07] != len(hash)*8 {
+		return "", 0, nil, fmt.Errorf("invalid hash length %d for version byte %#02x", len(hash), version)
+	}
+
+	return prefix, version >> 3, hash, nil
+}
+
+func convertbits(data []byte, frombits, tobits uint, pad bool) ([]byte, error) {
+	acc := 0
+	bits := uint(0)
+	var ret []byte
+	maxv := (1 << tobits) - 1
+	for _, value := range data {
+		acc = acc<<frombits | int(value)
+		bits += frombits
+		for bits >= tobits {
+			bits -= tobits
+			ret = append(ret, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			ret = append(ret, byte(acc<<(tobits-bits)&maxv))
+		}
+	} else if bits >= frombits {
+		return nil, fmt.Errorf("invalid incomplete group")
+	} else if (acc<<(tobits-bits))&maxv != 0 {
+		return nil, fmt.Errorf("non-zero padding")
+	}
+	return ret, nil
+}
@@ -0,0 +1,79 @@
+package cashaddr_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ottosch/pick-private/cashaddr"
+)
+
+type testData struct {
+	address  string
+	prefix   string
+	addrType byte
+	hash     string
+}
+
+var validTests = []testData{
+	{"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a", "bitcoincash", cashaddr.TypeP2PKH, "76a04053bda0a88bda5177b86a15c3b29f559873"},
+	{"bitcoincash:ppm2qsznhks23z7629mms6s4cwef74vcwvn0h829pq", "bitcoincash", cashaddr.TypeP2SH, "76a04053bda0a88bda5177b86a15c3b29f559873"},
+	{"bitcoincash:qr6m7j9njldwwzlg9v7v53unlr4jkmx6eylep8ekg2", "bitcoincash", cashaddr.TypeP2PKH, "f5bf48b397dae70be82b3cca4793f8eb2b6cdac9"},
+	{"pref:pr6m7j9njldwwzlg9v7v53unlr4jkmx6ey65nvtks5", "pref", cashaddr.TypeP2SH, "f5bf48b397dae70be82b3cca4793f8eb2b6cdac9"},
+	{"bitcoincash:qr95sy3j9xwd2ap32xkykttr4cvcu7as4y0qverfuy", "bitcoincash", cashaddr.TypeP2PKH, "cb481232299cd5743151ac4b2d63ae198e7bb0a9"},
+	{"bitcoincash:pr95sy3j9xwd2ap32xkykttr4cvcu7as4yc93ky28e", "bitcoincash", cashaddr.TypeP2SH, "cb481232299cd5743151ac4b2d63ae198e7bb0a9"},
+	{"bitcoincash:qp63uahgrxged4z5jswyt5dn5v3lzsem6cy4spdc2h", "bitcoincash", cashaddr.TypeP2PKH, "751e76e8199196d454941c45d1b3a323f1433bd6"},
+}
+
+var invalidTests = []string{
+	"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6b", // bad checksum
+	"bchtest:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a",     // wrong prefix
+	"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6A", // mixed case
+	"bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdxb",  // bad length
+}
+
+func TestEncode(t *testing.T) {
+	for _, test := range validTests {
+		hash, _ := hex.DecodeString(test.hash)
+		result, err := cashaddr.Encode(test.prefix, test.addrType, hash)
+
+		switch {
+		case err != nil:
+			t.Errorf("Encode for %s FAILED: %v\n", test.address, err)
+		case result != test.address:
+			t.Errorf("Encode for %s FAILED. Expected %s, got %s\n", test.hash, test.address, result)
+		default:
+			t.Logf("Encode passed: %s, %s\n", test.hash, test.address)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, test := range validTests {
+		prefix, addrType, hash, err := cashaddr.Decode(test.address, "")
+
+		switch {
+		case err != nil:
+			t.Errorf("Decode for %s FAILED: %v\n", test.address, err)
+		case prefix != test.prefix || addrType != test.addrType || hex.EncodeToString(hash) != test.hash:
+			t.Errorf("Decode for %s FAILED. Expected %s %d %s, got %s %d %x\n", test.address,
+				test.prefix, test.addrType, test.hash, prefix, addrType, hash)
+		default:
+			t.Logf("Decode passed: %s, %s\n", test.address, test.hash)
+		}
+	}
+
+	// prefix-less and uppercase forms
+	if _, _, _, err := cashaddr.Decode("QPM2QSZNHKS23Z7629MMS6S4CWEF74VCWVY22GDX6A", "bitcoincash"); err != nil {
+		t.Errorf("Decode without prefix FAILED: %v\n", err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, test := range invalidTests {
+		if _, _, _, err := cashaddr.Decode(test, ""); err == nil {
+			t.Errorf("Decode for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("Decode for %s failed: %v\n", test, err)
+		}
+	}
+}
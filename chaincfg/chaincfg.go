@@ -0,0 +1,259 @@
+package chaincfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Params holds the address and key prefixes of a network
+type Params struct {
+	Coin             string
+	Name             string
+	Testnet          bool // test networks share tprv/tpub versions and coin type 1
+	PubKeyHashAddrID byte
+	ScriptHashAddrID byte
+	PrivateKeyID     byte
+	Bech32HRP        string // empty if the coin has no segwit
+	CashAddrPrefix   string // Bitcoin Cash only
+	HDCoinType       uint32
+	SegWit           bool
+	Taproot          bool
+}
+
+// MainNet is the Bitcoin main network
+var MainNet = &Params{
+	Coin:             "btc",
+	Name:             "mainnet",
+	PubKeyHashAddrID: 0x00,
+	ScriptHashAddrID: 0x05,
+	PrivateKeyID:     0x80,
+	Bech32HRP:        "bc",
+	HDCoinType:       0,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// TestNet3 is the Bitcoin test network (version 3)
+var TestNet3 = &Params{
+	Coin:             "btc",
+	Name:             "testnet3",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xEF,
+	Bech32HRP:        "tb",
+	HDCoinType:       1,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// TestNet4 is the Bitcoin test network (version 4, BIP94)
+var TestNet4 = &Params{
+	Coin:             "btc",
+	Name:             "testnet4",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xEF,
+	Bech32HRP:        "tb",
+	HDCoinType:       1,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// SigNet is the default Bitcoin signet (BIP325)
+var SigNet = &Params{
+	Coin:             "btc",
+	Name:             "signet",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xEF,
+	Bech32HRP:        "tb",
+	HDCoinType:       1,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// RegTest is the Bitcoin regression test network
+var RegTest = &Params{
+	Coin:             "btc",
+	Name:             "regtest",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xEF,
+	Bech32HRP:        "bcrt",
+	HDCoinType:       1,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// LitecoinMainNet is the Litecoin main network (M-prefix P2SH)
+var LitecoinMainNet = &Params{
+	Coin:             "ltc",
+	Name:             "mainnet",
+	PubKeyHashAddrID: 0x30,
+	ScriptHashAddrID: 0x32,
+	PrivateKeyID:     0xB0,
+	Bech32HRP:        "ltc",
+	HDCoinType:       2,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// LitecoinTestNet is the Litecoin test network (Q-prefix P2SH)
+var LitecoinTestNet = &Params{
+	Coin:             "ltc",
+	Name:             "testnet",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0x3A,
+	PrivateKeyID:     0xEF,
+	Bech32HRP:        "tltc",
+	HDCoinType:       1,
+	SegWit:           true,
+	Taproot:          true,
+}
+
+// DogecoinMainNet is the Dogecoin main network (legacy addresses only)
+var DogecoinMainNet = &Params{
+	Coin:             "doge",
+	Name:             "mainnet",
+	PubKeyHashAddrID: 0x1E,
+	ScriptHashAddrID: 0x16,
+	PrivateKeyID:     0x9E,
+	HDCoinType:       3,
+}
+
+// DogecoinTestNet is the Dogecoin test network (legacy addresses only)
+var DogecoinTestNet = &Params{
+	Coin:             "doge",
+	Name:             "testnet",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x71,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xF1,
+	HDCoinType:       1,
+}
+
+// BitcoinCashMainNet is the Bitcoin Cash main network (no segwit)
+var BitcoinCashMainNet = &Params{
+	Coin:             "bch",
+	Name:             "mainnet",
+	PubKeyHashAddrID: 0x00,
+	ScriptHashAddrID: 0x05,
+	PrivateKeyID:     0x80,
+	CashAddrPrefix:   "bitcoincash",
+	HDCoinType:       145,
+}
+
+// BitcoinCashTestNet is the Bitcoin Cash test network (no segwit)
+var BitcoinCashTestNet = &Params{
+	Coin:             "bch",
+	Name:             "testnet",
+	Testnet:          true,
+	PubKeyHashAddrID: 0x6F,
+	ScriptHashAddrID: 0xC4,
+	PrivateKeyID:     0xEF,
+	CashAddrPrefix:   "bchtest",
+	HDCoinType:       1,
+}
+
+var registry = []*Params{
+	MainNet, TestNet3, TestNet4, SigNet, RegTest,
+	LitecoinMainNet, LitecoinTestNet,
+	DogecoinMainNet, DogecoinTestNet,
+	BitcoinCashMainNet, BitcoinCashTestNet,
+}
+
+var coinAliases = map[string]string{
+	"bitcoin":     "btc",
+	"litecoin":    "ltc",
+	"dogecoin":    "doge",
+	"bitcoincash": "bch",
+}
+
+var networkAliases = map[string]string{
+	"main": "mainnet",
+	"test": "testnet",
+}
+
+// Lookup returns the Bitcoin network with the given name (or alias)
+func Lookup(name string) (*Params, error) {
+	return LookupCoin("btc", name)
+}
+
+// LookupCoin returns the network of a coin with the given name (or alias)
+func LookupCoin(coin, name string) (*Params, error) {
+	coin, name = strings.ToLower(coin), strings.ToLower(name)
+	if alias, ok := coinAliases[coin]; ok {
+		coin = alias
+	}
+
+	names := Names(coin)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("unknown coin: %s (valid: %s)", coin, strings.Join(Coins(), ", "))
+	}
+
+	if alias, ok := networkAliases[name]; ok {
+		name = alias
+	}
+	if coin == "btc" && name == "testnet" {
+		name = "testnet3"
+	}
+
+	for _, params := range registry {
+		if params.Coin == coin && params.Name == name {
+			return params, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown %s network: %s (valid: %s)", coin, name, strings.Join(names, ", "))
+}
+
+// LookupAddressID returns the network of a base58 address version, and whether it is the P2SH
+// version. Networks sharing a version resolve to the first registered, Bitcoin first.
+func LookupAddressID(id byte) (*Params, bool, error) {
+	for _, params := range registry {
+		switch id {
+		case params.PubKeyHashAddrID:
+			return params, false, nil
+		case params.ScriptHashAddrID:
+			return params, true, nil
+		}
+	}
+	return nil, false, fmt.Errorf("unknown address version: %#02x", id)
+}
+
+// LookupHRP returns the network of a bech32 human-readable part, the first registered if shared
+func LookupHRP(hrp string) (*Params, error) {
+	for _, params := range registry {
+		if params.Bech32HRP != "" && params.Bech32HRP == hrp {
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown bech32 prefix: %s", hrp)
+}
+
+// Coins returns the registered coins
+func Coins() []string {
+	var coins []string
+	for _, params := range registry {
+		if len(coins) == 0 || coins[len(coins)-1] != params.Coin {
+			coins = append(coins, params.Coin)
+		}
+	}
+	return coins
+}
+
+// Names returns the network names of a coin
+func Names(coin string) []string {
+	var names []string
+	for _, params := range registry {
+		if params.Coin == coin {
+			names = append(names, params.Name)
+		}
+	}
+	return names
+}
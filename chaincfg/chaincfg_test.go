@@ -0,0 +1,117 @@
+package chaincfg_test
+
+import (
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+)
+
+type lookupTestData struct {
+	input  string
+	output *chaincfg.Params
+}
+
+var lookupTests = []lookupTestData{
+	{"mainnet", chaincfg.MainNet},
+	{"main", chaincfg.MainNet},
+	{"testnet", chaincfg.TestNet3},
+	{"testnet3", chaincfg.TestNet3},
+	{"TestNet4", chaincfg.TestNet4},
+	{"signet", chaincfg.SigNet},
+	{"regtest", chaincfg.RegTest},
+}
+
+func TestLookup(t *testing.T) {
+	for _, test := range lookupTests {
+		result, err := chaincfg.Lookup(test.input)
+
+		switch {
+		case err != nil:
+			t.Errorf("Lookup for %s FAILED: %v\n", test.input, err)
+		case result != test.output:
+			t.Errorf("Lookup for %s FAILED. Expected %s, got %s\n", test.input, test.output.Name, result.Name)
+		default:
+			t.Logf("Lookup passed: %s, %s\n", test.input, result.Name)
+		}
+	}
+
+	if _, err := chaincfg.Lookup("bogus"); err == nil {
+		t.Errorf("Lookup for bogus passed, should've failed: FAIL\n")
+	}
+}
+
+type lookupCoinTestData struct {
+	coin    string
+	network string
+	output  *chaincfg.Params
+}
+
+var lookupCoinTests = []lookupCoinTestData{
+	{"btc", "testnet", chaincfg.TestNet3},
+	{"bitcoin", "regtest", chaincfg.RegTest},
+	{"ltc", "mainnet", chaincfg.LitecoinMainNet},
+	{"litecoin", "test", chaincfg.LitecoinTestNet},
+	{"doge", "testnet", chaincfg.DogecoinTestNet},
+	{"BCH", "main", chaincfg.BitcoinCashMainNet},
+}
+
+func TestLookupCoin(t *testing.T) {
+	for _, test := range lookupCoinTests {
+		result, err := chaincfg.LookupCoin(test.coin, test.network)
+
+		switch {
+		case err != nil:
+			t.Errorf("LookupCoin for %s %s FAILED: %v\n", test.coin, test.network, err)
+		case result != test.output:
+			t.Errorf("LookupCoin for %s %s FAILED. Expected %s %s, got %s %s\n", test.coin, test.network,
+				test.output.Coin, test.output.Name, result.Coin, result.Name)
+		default:
+			t.Logf("LookupCoin passed: %s %s, %s %s\n", test.coin, test.network, result.Coin, result.Name)
+		}
+	}
+
+	for _, invalid := range [][2]string{{"xyz", "mainnet"}, {"doge", "regtest"}, {"ltc", "signet"}} {
+		if _, err := chaincfg.LookupCoin(invalid[0], invalid[1]); err == nil {
+			t.Errorf("LookupCoin for %s %s passed, should've failed: FAIL\n", invalid[0], invalid[1])
+		}
+	}
+}
+
+func TestLookupAddress(t *testing.T) {
+	tests := []struct {
+		id         byte
+		scriptHash bool
+		output     *chaincfg.Params
+	}{
+		{0x00, false, chaincfg.MainNet},
+		{0xC4, true, chaincfg.TestNet3},
+		{0x32, true, chaincfg.LitecoinMainNet},
+		{0x1E, false, chaincfg.DogecoinMainNet},
+		{0x71, false, chaincfg.DogecoinTestNet},
+	}
+
+	for _, test := range tests {
+		result, scriptHash, err := chaincfg.LookupAddressID(test.id)
+		switch {
+		case err != nil:
+			t.Errorf("LookupAddressID for %02x FAILED: %v\n", test.id, err)
+		case result != test.output || scriptHash != test.scriptHash:
+			t.Errorf("LookupAddressID for %02x FAILED. Expected %s %s %t, got %s %s %t\n", test.id,
+				test.output.Coin, test.output.Name, test.scriptHash, result.Coin, result.Name, scriptHash)
+		default:
+			t.Logf("LookupAddressID passed: %02x, %s %s\n", test.id, result.Coin, result.Name)
+		}
+	}
+	if _, _, err := chaincfg.LookupAddressID(0x80); err == nil {
+		t.Errorf("LookupAddressID for 80 passed, should've failed: FAIL\n")
+	}
+
+	for hrp, expected := range map[string]*chaincfg.Params{"bc": chaincfg.MainNet, "tb": chaincfg.TestNet3, "tltc": chaincfg.LitecoinTestNet} {
+		if result, err := chaincfg.LookupHRP(hrp); err != nil || result != expected {
+			t.Errorf("LookupHRP for %s FAILED: %v\n", hrp, err)
+		}
+	}
+	if _, err := chaincfg.LookupHRP(""); err == nil {
+		t.Errorf("LookupHRP for an empty prefix passed, should've failed: FAIL\n")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ottosch/pick-private/contract"
+	"github.com/ottosch/pick-private/keys"
+)
+
+func runContract(args []string) {
+	flags := flag.NewFlagSet("contract", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s contract [options] timelock key | htlc receiver refund | 2of2 key cosigner\n", os.Args[0])
+		fmt.Println("\ntimelock: key can spend after the timelock")
+		fmt.Println("htlc:     receiver can spend with the preimage of -hash, refund after the timelock")
+		fmt.Println("2of2:     key and cosigner can spend together, key alone after the timelock")
+		fmt.Println("\nKeys are compressed public keys, or private, extended or mnemonic keys (quoted) whose public key is used.")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s contract -after 800000 timelock 02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8\n", os.Args[0])
+		fmt.Printf("  %s contract -hash a12871fee210fb8619291eaea194581cbd2531e4b23759d225f6806923f63222 -older 144 htlc 1 2\n", os.Args[0])
+		fmt.Printf("  %s contract -older 1008 2of2 1 2\n", os.Args[0])
+	}
+
+	var afterFlag, olderFlag uint64
+	var hashFlag, preimageFlag string
+	flags.Uint64Var(&afterFlag, "after", 0, "absolute timelock (OP_CHECKLOCKTIMEVERIFY): block height, or time from 500000000")
+	flags.Uint64Var(&olderFlag, "older", 0, "relative timelock (OP_CHECKSEQUENCEVERIFY): blocks, or BIP68 sequence value")
+	flags.StringVar(&hashFlag, "hash", "", "htlc: SHA256 payment hash (hex)")
+	flags.StringVar(&preimageFlag, "preimage", "", "htlc: 32-byte preimage (hex) whose SHA256 is the payment hash")
+	addKeyFlags(flags)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) < 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	var lock contract.Timelock
+	switch {
+	case afterFlag != 0 && olderFlag != 0, afterFlag == 0 && olderFlag == 0:
+		fmt.Fprintln(os.Stderr, "exactly one of -after or -older is required")
+		os.Exit(1)
+	case afterFlag > 0xffffffff || olderFlag > 0xffffffff:
+		fmt.Fprintln(os.Stderr, "timelock out of range")
+		os.Exit(1)
+	case afterFlag != 0:
+		lock = contract.After(uint32(afterFlag))
+	default:
+		lock = contract.Older(uint32(olderFlag))
+	}
+
+	var contractKeys []keys.PublicKey
+	for _, key := range positional[1:] {
+		pubkey := parsePublicKey([]string{key})
+		// the scripts also have segwit forms, which take compressed keys only
+		if len(pubkey) == 65 {
+			fmt.Fprintf(os.Stderr, "uncompressed keys are not supported in contracts, use the compressed key: %x\n", pubkey)
+			os.Exit(1)
+		}
+		pub, err := keys.PublicKeyFromBytes(pubkey, params)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		contractKeys = append(contractKeys, pub)
+	}
+
+	var c *contract.Contract
+	var err error
+	var title string
+	switch name, count := positional[0], len(contractKeys); {
+	case name == "timelock" && count == 1:
+		title = "Timelocked key"
+		c, err = contract.TimelockedKey(&contractKeys[0], lock, params)
+	case name == "htlc" && count == 2:
+		title = "HTLC"
+		var hash []byte
+		if hash, err = htlcHash(hashFlag, preimageFlag); err == nil {
+			c, err = contract.HTLC(&contractKeys[0], &contractKeys[1], hash, lock, params)
+		}
+	case name == "2of2" && count == 2:
+		title = "2-of-2 or timeout"
+		c, err = contract.TwoOfTwoOrTimeout(&contractKeys[0], &contractKeys[1], lock, params)
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[%s]\n", title)
+	fmt.Printf("    Script: %x\n", c.Script())
+	fmt.Printf("       ASM: %s\n", scriptAsm(hex.EncodeToString(c.Script())))
+	fmt.Printf("Miniscript: %s\n", c.Miniscript())
+	fmt.Println()
+
+	fmt.Println("[P2SH]")
+	fmt.Printf("   Address: %s\n", c.ToAddressP2SH())
+	fmt.Printf("    Script: %s\n", c.ToScriptP2SH())
+	fmt.Printf("Descriptor: %s\n", c.DescriptorP2SH())
+	fmt.Println()
+
+	if !params.SegWit {
+		return
+	}
+
+	fmt.Println("[P2SH-P2WSH]")
+	fmt.Printf("   Address: %s\n", c.ToAddressP2SHP2WSH())
+	fmt.Printf("    Script: %s\n", c.ToScriptP2SHP2WSH())
+	fmt.Printf("Descriptor: %s\n", c.DescriptorP2SHP2WSH())
+	fmt.Println()
+
+	fmt.Println("[P2WSH]")
+	fmt.Printf("   Address: %s\n", c.ToAddressP2WSH())
+	fmt.Printf("    Script: %s\n", c.ToScriptP2WSH())
+	fmt.Printf("Descriptor: %s\n", c.DescriptorP2WSH())
+	fmt.Println()
+}
+
+// htlcHash returns the payment hash, given or as the SHA256 of the preimage
+func htlcHash(hashHex, preimageHex string) ([]byte, error) {
+	switch {
+	case hashHex != "" && preimageHex != "":
+		return nil, fmt.Errorf("only one of -hash or -preimage can be given")
+	case preimageHex != "":
+		preimage, err := hex.DecodeString(preimageHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid preimage: %v", err)
+		}
+		// the script checks OP_SIZE 32, as miniscript sha256() does
+		if len(preimage) != 32 {
+			return nil, fmt.Errorf("invalid preimage length: %d (32 bytes)", len(preimage))
+		}
+		hash := sha256.Sum256(preimage)
+		return hash[:], nil
+	case hashHex != "":
+		hash, err := hex.DecodeString(hashHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash: %v", err)
+		}
+		return hash, nil
+	default:
+		return nil, fmt.Errorf("htlc needs -hash or -preimage")
+	}
+}
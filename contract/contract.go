@@ -0,0 +1,145 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/scripthash"
+)
+
+// maxTimelock is the miniscript limit of after() and older() values
+const maxTimelock = 1<<31 - 1
+
+// Timelock is an absolute (OP_CHECKLOCKTIMEVERIFY, block height or time) or relative
+// (OP_CHECKSEQUENCEVERIFY, BIP68 sequence) lock
+type Timelock struct {
+	Value    uint32
+	Relative bool
+}
+
+// After returns an absolute timelock: a block height, or a time from 500000000
+func After(value uint32) Timelock {
+	return Timelock{value, false}
+}
+
+// Older returns a relative timelock: a BIP68 sequence value (blocks, or 512-second units with 1<<22)
+func Older(value uint32) Timelock {
+	return Timelock{value, true}
+}
+
+func (lock Timelock) validate() error {
+	if lock.Value < 1 || lock.Value > maxTimelock {
+		return fmt.Errorf("invalid timelock: %d (1 to %d)", lock.Value, maxTimelock)
+	}
+	return nil
+}
+
+// script returns <n> OP_CHECKLOCKTIMEVERIFY or <n> OP_CHECKSEQUENCEVERIFY
+func (lock Timelock) script() []byte {
+	if lock.Relative {
+		return append(script.PushInt(int64(lock.Value)), script.OpCheckSequenceVerify)
+	}
+	return append(script.PushInt(int64(lock.Value)), script.OpCheckLockTimeVerify)
+}
+
+// miniscript returns the after() or older() fragment
+func (lock Timelock) miniscript() string {
+	if lock.Relative {
+		return fmt.Sprintf("older(%d)", lock.Value)
+	}
+	return fmt.Sprintf("after(%d)", lock.Value)
+}
+
+// Contract is a witness script with its miniscript, and its P2SH, P2SH-P2WSH and P2WSH forms
+type Contract struct {
+	*scripthash.Outputs
+}
+
+// TimelockedKey creates a single-sig script spendable only after the timelock:
+// and_v(v:pk(key),after(n)) or and_v(v:pk(key),older(n))
+func TimelockedKey(key descriptor.Key, lock Timelock, params *chaincfg.Params) (*Contract, error) {
+	if err := lock.validate(); err != nil {
+		return nil, err
+	}
+
+	s := checkSig(key, true)
+	s = append(s, lock.script()...)
+
+	miniscript := fmt.Sprintf("and_v(v:pk(%x),%s)", key.PublicKey(), lock.miniscript())
+	return &Contract{scripthash.New(s, miniscript, params)}, nil
+}
+
+// HTLC creates a hashed-timelock contract: the receiver spends with the SHA256 preimage of hash,
+// the refund key after the timelock. andor(pk(receiver),sha256(hash),and_v(v:pk(refund),after(n)))
+func HTLC(receiver, refund descriptor.Key, hash []byte, lock Timelock, params *chaincfg.Params) (*Contract, error) {
+	if len(hash) != 32 {
+		return nil, fmt.Errorf("invalid SHA256 hash length: %d", len(hash))
+	}
+	if err := lock.validate(); err != nil {
+		return nil, err
+	}
+	if err := distinctKeys(receiver, refund); err != nil {
+		return nil, err
+	}
+
+	// <receiver> OP_CHECKSIG OP_NOTIF <refund> OP_CHECKSIGVERIFY <lock>
+	// OP_ELSE OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 <hash> OP_EQUAL OP_ENDIF
+	s := checkSig(receiver, false)
+	s = append(s, script.OpNotIf)
+	s = append(s, checkSig(refund, true)...)
+	s = append(s, lock.script()...)
+	s = append(s, script.OpElse, script.OpSize)
+	s = append(s, script.PushInt(32)...)
+	s = append(s, script.OpEqualVerify, script.OpSha256)
+	s = append(s, script.PushData(hash)...)
+	s = append(s, script.OpEqual, script.OpEndIf)
+
+	miniscript := fmt.Sprintf("andor(pk(%x),sha256(%x),and_v(v:pk(%x),%s))", receiver.PublicKey(), hash, refund.PublicKey(), lock.miniscript())
+	return &Contract{scripthash.New(s, miniscript, params)}, nil
+}
+
+// TwoOfTwoOrTimeout creates a script spendable by key and cosigner together, or by key alone
+// after the timelock: and_v(v:pk(key),or_d(pk(cosigner),after(n)))
+func TwoOfTwoOrTimeout(key, cosigner descriptor.Key, lock Timelock, params *chaincfg.Params) (*Contract, error) {
+	if err := lock.validate(); err != nil {
+		return nil, err
+	}
+	if err := distinctKeys(key, cosigner); err != nil {
+		return nil, err
+	}
+
+	// <key> OP_CHECKSIGVERIFY <cosigner> OP_CHECKSIG OP_IFDUP OP_NOTIF <lock> OP_ENDIF
+	s := checkSig(key, true)
+	s = append(s, checkSig(cosigner, false)...)
+	s = append(s, script.OpIfDup, script.OpNotIf)
+	s = append(s, lock.script()...)
+	s = append(s, script.OpEndIf)
+
+	miniscript := fmt.Sprintf("and_v(v:pk(%x),or_d(pk(%x),%s))", key.PublicKey(), cosigner.PublicKey(), lock.miniscript())
+	return &Contract{scripthash.New(s, miniscript, params)}, nil
+}
+
+// distinctKeys rejects a repeated key, which makes the miniscript not sane
+func distinctKeys(a, b descriptor.Key) error {
+	if bytes.Equal(a.PublicKey(), b.PublicKey()) {
+		return fmt.Errorf("duplicate key: %x", a.PublicKey())
+	}
+	return nil
+}
+
+// checkSig returns <pubkey> OP_CHECKSIG, or OP_CHECKSIGVERIFY if verify
+func checkSig(key descriptor.Key, verify bool) []byte {
+	s := script.PushData(key.PublicKey())
+	if verify {
+		return append(s, script.OpCheckSigVerify)
+	}
+	return append(s, script.OpCheckSig)
+}
+
+// Miniscript returns the miniscript expression of the script
+func (c *Contract) Miniscript() string {
+	return c.Expression()
+}
@@ -0,0 +1,190 @@
+package contract_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/contract"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+)
+
+const (
+	pub1 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	pub2 = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+)
+
+var (
+	key1     = keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+	key2     = keys.FromBigInt(big.NewInt(2), chaincfg.MainNet)
+	preimage = []byte("pick-private htlc preimage 32 by")
+	digest   = crypto.Hash256([]byte("pick-private"))
+)
+
+// spendTestData is a witness (without the witness script) and the spending transaction fields
+type spendTestData struct {
+	witness  []string
+	lockTime uint32
+	sequence uint32
+	valid    bool
+}
+
+type contractTestData struct {
+	name       string
+	contract   func() (*contract.Contract, error)
+	asm        string
+	miniscript string
+	spends     []spendTestData
+}
+
+func sign(key keys.PrivateKey) string {
+	sig, _ := key.SignECDSA(digest)
+	return hex.EncodeToString(append(sig, 0x01))
+}
+
+func TestContracts(t *testing.T) {
+	hash := sha256.Sum256(preimage)
+	hashHex := hex.EncodeToString(hash[:])
+	sig1, sig2 := sign(key1), sign(key2)
+	preimageHex := hex.EncodeToString(preimage)
+
+	tests := []contractTestData{
+		{
+			name: "CLTV key",
+			contract: func() (*contract.Contract, error) {
+				return contract.TimelockedKey(&key1, contract.After(800000), chaincfg.MainNet)
+			},
+			asm:        pub1 + " OP_CHECKSIGVERIFY 800000 OP_CHECKLOCKTIMEVERIFY",
+			miniscript: "and_v(v:pk(" + pub1 + "),after(800000))",
+			spends: []spendTestData{
+				{[]string{sig1}, 800000, 0xfffffffe, true},
+				{[]string{sig1}, 799999, 0xfffffffe, false},
+				{[]string{sig2}, 800000, 0xfffffffe, false},
+			},
+		},
+		{
+			name: "CSV key",
+			contract: func() (*contract.Contract, error) {
+				return contract.TimelockedKey(&key1, contract.Older(144), chaincfg.MainNet)
+			},
+			asm:        pub1 + " OP_CHECKSIGVERIFY 144 OP_CHECKSEQUENCEVERIFY",
+			miniscript: "and_v(v:pk(" + pub1 + "),older(144))",
+			spends: []spendTestData{
+				{[]string{sig1}, 0, 144, true},
+				{[]string{sig1}, 0, 143, false},
+			},
+		},
+		{
+			name: "HTLC",
+			contract: func() (*contract.Contract, error) {
+				return contract.HTLC(&key1, &key2, hash[:], contract.After(800000), chaincfg.MainNet)
+			},
+			asm:        pub1 + " OP_CHECKSIG OP_NOTIF " + pub2 + " OP_CHECKSIGVERIFY 800000 OP_CHECKLOCKTIMEVERIFY OP_ELSE OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 " + hashHex + " OP_EQUAL OP_ENDIF",
+			miniscript: "andor(pk(" + pub1 + "),sha256(" + hashHex + "),and_v(v:pk(" + pub2 + "),after(800000)))",
+			spends: []spendTestData{
+				{[]string{preimageHex, sig1}, 0, 0xfffffffe, true},
+				{[]string{hex.EncodeToString(hash[:]), sig1}, 0, 0xfffffffe, false},
+				{[]string{sig2, ""}, 800000, 0xfffffffe, true},
+				{[]string{sig2, ""}, 799999, 0xfffffffe, false},
+			},
+		},
+		{
+			name: "2-of-2 or timeout",
+			contract: func() (*contract.Contract, error) {
+				return contract.TwoOfTwoOrTimeout(&key1, &key2, contract.Older(1008), chaincfg.MainNet)
+			},
+			asm:        pub1 + " OP_CHECKSIGVERIFY " + pub2 + " OP_CHECKSIG OP_IFDUP OP_NOTIF 1008 OP_CHECKSEQUENCEVERIFY OP_ENDIF",
+			miniscript: "and_v(v:pk(" + pub1 + "),or_d(pk(" + pub2 + "),older(1008)))",
+			spends: []spendTestData{
+				{[]string{sig2, sig1}, 0, 0, true},
+				{[]string{"", sig1}, 0, 1008, true},
+				{[]string{"", sig1}, 0, 1007, false},
+				{[]string{sig2, ""}, 0, 1008, false},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		c, err := test.contract()
+		if err != nil {
+			t.Errorf("%s FAILED: %v\n", test.name, err)
+			continue
+		}
+
+		if asm, _ := script.Disassemble(c.Script()); asm != test.asm {
+			t.Errorf("%s script FAILED. Expected %s, got %s\n", test.name, test.asm, asm)
+		} else {
+			t.Logf("%s script passed: %s\n", test.name, asm)
+		}
+		if c.Miniscript() != test.miniscript {
+			t.Errorf("%s miniscript FAILED. Expected %s, got %s\n", test.name, test.miniscript, c.Miniscript())
+		}
+
+		descriptors := map[string]string{
+			"sh(" + test.miniscript + ")":      c.DescriptorP2SH(),
+			"sh(wsh(" + test.miniscript + "))": c.DescriptorP2SHP2WSH(),
+			"wsh(" + test.miniscript + ")":     c.DescriptorP2WSH(),
+		}
+		for expected, desc := range descriptors {
+			if _, err := descriptor.VerifyChecksum(desc, true); err != nil || desc[:len(desc)-9] != expected {
+				t.Errorf("%s descriptor FAILED. Expected %s#checksum, got %s\n", test.name, expected, desc)
+			}
+		}
+
+		addresses := map[string]string{
+			c.ToScriptP2SH():      c.ToAddressP2SH(),
+			c.ToScriptP2SHP2WSH(): c.ToAddressP2SHP2WSH(),
+			c.ToScriptP2WSH():     c.ToAddressP2WSH(),
+		}
+		for scriptHex, expected := range addresses {
+			data, _ := hex.DecodeString(scriptHex)
+			if addr, err := address.FromScript(data, chaincfg.MainNet); err != nil || addr != expected {
+				t.Errorf("%s address FAILED. Expected %s, got %s (%v)\n", test.name, expected, addr, err)
+			}
+		}
+
+		scriptPubKey, _ := hex.DecodeString(c.ToScriptP2WSH())
+		for i, spend := range test.spends {
+			var witness [][]byte
+			for _, item := range spend.witness {
+				data, _ := hex.DecodeString(item)
+				witness = append(witness, data)
+			}
+			witness = append(witness, c.Script())
+
+			ctx := &script.Context{SigHash: digest, LockTime: spend.lockTime, Sequence: spend.sequence, Version: 2}
+			err := script.Verify(nil, witness, scriptPubKey, ctx, nil)
+			if (err == nil) != spend.valid {
+				t.Errorf("%s spend %d FAILED. Expected valid %t, got %v\n", test.name, i, spend.valid, err)
+			} else {
+				t.Logf("%s spend %d passed: %v\n", test.name, i, err)
+			}
+		}
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := contract.TimelockedKey(&key1, contract.After(0), chaincfg.MainNet); err == nil {
+		t.Errorf("TimelockedKey with a zero timelock passed, should've failed: FAIL\n")
+	}
+	if _, err := contract.TwoOfTwoOrTimeout(&key1, &key2, contract.Older(1<<31), chaincfg.MainNet); err == nil {
+		t.Errorf("TwoOfTwoOrTimeout with an out of range timelock passed, should've failed: FAIL\n")
+	}
+	if _, err := contract.HTLC(&key1, &key2, preimage[:20], contract.After(1), chaincfg.MainNet); err == nil {
+		t.Errorf("HTLC with a 20-byte hash passed, should've failed: FAIL\n")
+	}
+
+	hash := sha256.Sum256(preimage)
+	if _, err := contract.HTLC(&key1, &key1, hash[:], contract.After(1), chaincfg.MainNet); err == nil {
+		t.Errorf("HTLC with a duplicate key passed, should've failed: FAIL\n")
+	}
+	if _, err := contract.TwoOfTwoOrTimeout(&key2, &key2, contract.Older(144), chaincfg.MainNet); err == nil {
+		t.Errorf("TwoOfTwoOrTimeout with a duplicate key passed, should've failed: FAIL\n")
+	}
+}
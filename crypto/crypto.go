@@ -4,12 +4,18 @@ import (
 	"crypto/sha256"
 
 	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
 )
 
 func Hash160(data []byte) []byte {
 	sha256 := sha256.Sum256(data)
+	return Ripemd160(sha256[:])
+}
+
+// Ripemd160 returns the RIPEMD-160 hash of data
+func Ripemd160(data []byte) []byte {
 	ripe := ripemd160.New()
-	ripe.Write(sha256[:])
+	ripe.Write(data)
 	return ripe.Sum(nil)
 }
 
@@ -18,3 +24,22 @@ func Hash256(data []byte) []byte {
 	digest = sha256.Sum256(digest[:])
 	return digest[:]
 }
+
+// TaggedHash returns the BIP340 tagged hash SHA256(SHA256(tag) || SHA256(tag) || data...)
+func TaggedHash(tag string, data ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// Keccak256 returns the legacy Keccak-256 hash used by Ethereum (not NIST SHA3-256)
+func Keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -26,6 +26,24 @@ var hash256Tests = []testData{
 	{"05910473597fa5a7289e29a98bccb87be363606709", "0b94a623d50876c16cf5b84265473666b78891fae0d3e052c45af37076be6714"},
 }
 
+// RIPEMD-160 reference vectors
+var ripemd160Tests = []testData{
+	{"", "9c1185a5c5e9fc54612808977ee8f548b2258d31"},
+	{"616263", "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc"},
+}
+
+func TestRipemd160(t *testing.T) {
+	for _, test := range ripemd160Tests {
+		input, _ := hex.DecodeString(test.input)
+		result := hex.EncodeToString(crypto.Ripemd160(input))
+		if result != test.output {
+			t.Errorf("Ripemd160 for %s FAILED. Expected %s, got %s\n", test.input, test.output, result)
+		} else {
+			t.Logf("Ripemd160 passed: %s, %s\n", test.input, test.output)
+		}
+	}
+}
+
 func TestHash160(t *testing.T) {
 	for _, test := range hash160Tests {
 		input, _ := hex.DecodeString(test.input)
@@ -49,3 +67,48 @@ func TestHash256(t *testing.T) {
 		}
 	}
 }
+
+type taggedHashTestData struct {
+	tag    string
+	input  string
+	output string
+}
+
+var taggedHashTests = []taggedHashTestData{
+	{"TapTweak", "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "3cf5216d476a5e637bf0da674e50ddf55c403270dd36494dfcca438132fa30e7"},
+	{"BIP0340/challenge", "", "c216d352f5818b7b4beacd4ae0a26fe888080823d2a598856661bcd54f1b3713"},
+}
+
+func TestTaggedHash(t *testing.T) {
+	for _, test := range taggedHashTests {
+		input, _ := hex.DecodeString(test.input)
+		result := hex.EncodeToString(crypto.TaggedHash(test.tag, input))
+		if result != test.output {
+			t.Errorf("TaggedHash for [%s] %s FAILED. Expected %s, got %s\n", test.tag, test.input, test.output, result)
+		} else {
+			t.Logf("TaggedHash passed: [%s] %s, %s\n", test.tag, test.input, test.output)
+		}
+	}
+}
+
+type keccakTestData struct {
+	input  string
+	output string
+}
+
+var keccakTests = []keccakTestData{
+	{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+	{"616263", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+}
+
+func TestKeccak256(t *testing.T) {
+	for _, test := range keccakTests {
+		input, _ := hex.DecodeString(test.input)
+		result := hex.EncodeToString(crypto.Keccak256(input))
+		if result != test.output {
+			t.Errorf("Keccak256 for %s FAILED. Expected %s, got %s\n", test.input, test.output, result)
+		} else {
+			t.Logf("Keccak256 passed: %s, %s\n", test.input, test.output)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ottosch/pick-private/descriptor"
+)
+
+func runDescriptor(args []string) {
+	flags := flag.NewFlagSet("descriptor", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s descriptor [options] descriptor\n", os.Args[0])
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s descriptor 'wpkh(02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9)'\n", os.Args[0])
+		fmt.Printf("  %s descriptor \"tr([73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ/0/*)\" -range 0:20\n", os.Args[0])
+	}
+
+	var rangeFlag, coinFlag, networkFlag string
+	flags.StringVar(&rangeFlag, "range", "0:0", "index range for a ranged descriptor, as start:end (inclusive) or a single index")
+	flags.StringVar(&coinFlag, "coin", "btc", coinUsage)
+	flags.StringVar(&networkFlag, "network", "", networkUsage+". Defaults to mainnet, or to the network of the extended keys")
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	desc, err := descriptor.Parse(strings.Join(positional, ""))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if networkFlag == "" {
+		networkFlag = "mainnet"
+		if desc.Testnet() {
+			networkFlag = "testnet"
+		}
+	}
+	params := lookupNetwork(coinFlag, networkFlag)
+
+	start, end, err := parseRange(rangeFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !desc.IsRange() {
+		start, end = 0, 0
+	}
+
+	fmt.Println("[Descriptor]")
+	fmt.Println(desc)
+
+	for index := start; index <= end; index++ {
+		script, err := desc.Script(index)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		if desc.IsRange() {
+			fmt.Printf("[%d]\n", index)
+		}
+		if address, err := desc.Address(index, params); err == nil {
+			fmt.Printf("Address: %s\n", address)
+		}
+		fmt.Printf(" Script: %x\n", script)
+
+		if index == end {
+			break
+		}
+	}
+}
+
+func parseRange(value string) (uint32, uint32, error) {
+	bounds := strings.SplitN(value, ":", 2)
+	start, err := strconv.ParseUint(bounds[0], 10, 31)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range: %s", value)
+	}
+
+	end := start
+	if len(bounds) == 2 {
+		if end, err = strconv.ParseUint(bounds[1], 10, 31); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range: %s", value)
+		}
+	}
+	return uint32(start), uint32(end), nil
+}
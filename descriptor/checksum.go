@@ -0,0 +1,104 @@
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	inputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	checksumLength  = 8
+)
+
+var generator = []uint64{0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd}
+
+func polymod(symbols []uint64) uint64 {
+	chk := uint64(1)
+	for _, value := range symbols {
+		top := chk >> 35
+		chk = (chk&0x7ffffffff)<<5 ^ value
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func expand(desc string) ([]uint64, error) {
+	var symbols, groups []uint64
+	for p, c := range desc {
+		v := strings.IndexRune(inputCharset, c)
+		if v < 0 {
+			return nil, fmt.Errorf("invalid character in descriptor: %q at position %d", c, p)
+		}
+
+		symbols = append(symbols, uint64(v&31))
+		groups = append(groups, uint64(v>>5))
+		if len(groups) == 3 {
+			symbols = append(symbols, groups[0]*9+groups[1]*3+groups[2])
+			groups = groups[:0]
+		}
+	}
+
+	switch len(groups) {
+	case 1:
+		symbols = append(symbols, groups[0])
+	case 2:
+		symbols = append(symbols, groups[0]*3+groups[1])
+	}
+	return symbols, nil
+}
+
+// Checksum returns the BIP380 8-character checksum of a descriptor (without "#").
+func Checksum(desc string) (string, error) {
+	symbols, err := expand(desc)
+	if err != nil {
+		return "", err
+	}
+
+	mod := polymod(append(symbols, make([]uint64, checksumLength)...)) ^ 1
+
+	var ret strings.Builder
+	for i := 0; i < checksumLength; i++ {
+		ret.WriteByte(checksumCharset[(mod>>(5*(checksumLength-1-i)))&31])
+	}
+	return ret.String(), nil
+}
+
+// AddChecksum returns the descriptor followed by "#" and its checksum.
+func AddChecksum(desc string) (string, error) {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		return "", err
+	}
+	return desc + "#" + checksum, nil
+}
+
+// VerifyChecksum splits "desc#checksum", validating the checksum if present.
+// When required is true, a missing checksum is an error.
+func VerifyChecksum(desc string, required bool) (string, error) {
+	pos := strings.LastIndex(desc, "#")
+	if pos < 0 {
+		if required {
+			return "", fmt.Errorf("missing checksum: %s", desc)
+		}
+		return desc, nil
+	}
+
+	body, checksum := desc[:pos], desc[pos+1:]
+	if len(checksum) != checksumLength {
+		return "", fmt.Errorf("invalid checksum length: %d", len(checksum))
+	}
+
+	expected, err := Checksum(body)
+	if err != nil {
+		return "", err
+	}
+	if checksum != expected {
+		return "", fmt.Errorf("invalid checksum: expected %s, got %s", expected, checksum)
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package descriptor
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ottosch/pick-private/keys"
+)
+
+// Key is a private key or a watch-only public key
+type Key interface {
+	PublicKey() []byte
+	PublicKeyUncompressed() []byte
+	TaprootInternalKey() []byte
+}
+
+// Legacy returns the pkh() descriptor of the compressed public key (or WIF if private)
+func Legacy(key Key, private bool) string {
+	return withChecksum("pkh(%s)", keyExpression(key, true, private))
+}
+
+// LegacyUncompressed returns the pkh() descriptor of the uncompressed public key (or WIF if private)
+func LegacyUncompressed(key Key, private bool) string {
+	return withChecksum("pkh(%s)", keyExpression(key, false, private))
+}
+
+// SegWitCompat returns the sh(wpkh()) descriptor
+func SegWitCompat(key Key, private bool) string {
+	return withChecksum("sh(wpkh(%s))", keyExpression(key, true, private))
+}
+
+// SegWit returns the wpkh() descriptor
+func SegWit(key Key, private bool) string {
+	return withChecksum("wpkh(%s)", keyExpression(key, true, private))
+}
+
+// Taproot returns the key-path only tr() descriptor, with the x-only internal key (or WIF if private)
+func Taproot(key Key, private bool) string {
+	expression := hex.EncodeToString(key.TaprootInternalKey())
+	if priv, ok := key.(*keys.PrivateKey); ok && private {
+		expression = priv.ToWIF()
+	}
+	return withChecksum("tr(%s)", expression)
+}
+
+func keyExpression(key Key, compressed, private bool) string {
+	priv, ok := key.(*keys.PrivateKey)
+	private = private && ok
+
+	switch {
+	case private && compressed:
+		return priv.ToWIF()
+	case private && !compressed:
+		return priv.ToWIFUncompressed()
+	case compressed:
+		return hex.EncodeToString(key.PublicKey())
+	default:
+		return hex.EncodeToString(key.PublicKeyUncompressed())
+	}
+}
+
+func withChecksum(format string, key string) string {
+	desc, _ := AddChecksum(fmt.Sprintf(format, key))
+	return desc
+}
@@ -0,0 +1,217 @@
+package descriptor_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/keys"
+)
+
+type checksumTestData struct {
+	input  string
+	output string
+}
+
+var checksumTests = []checksumTestData{
+	{"raw(deadbeef)", "89f8spxm"},
+	{"addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)", "02wpgw69"},
+	{"pkh(02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5)", "8fhd9pwu"},
+}
+
+var invalidChecksums = []string{
+	"raw(deadbeef)#",          // missing checksum
+	"raw(deadbeef)#89f8spxmx", // too long checksum
+	"raw(deadbeef)#89f8spx",   // too short checksum
+	"raw(dedbeef)#89f8spxm",   // error in payload
+	"raw(deadbeef)#9f8spxm",   // error in checksum
+	"raw(Ü)#00000000",         // invalid characters in payload
+}
+
+type generationTestData struct {
+	input              *big.Int
+	legacy             string
+	legacyUncompressed string
+	segWitCompat       string
+	segWit             string
+	taproot            string
+}
+
+var publicTests = []generationTestData{
+	{
+		input:              big.NewInt(1),
+		legacy:             "pkh(0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#e48zzw02",
+		legacyUncompressed: "pkh(0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8)#zvxck6mv",
+		segWitCompat:       "sh(wpkh(0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798))#jqtwwlah",
+		segWit:             "wpkh(0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#ucxz0gak",
+		taproot:            "tr(79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798)#gxjkeue2",
+	},
+}
+
+var privateTests = []generationTestData{
+	{
+		input:              big.NewInt(1),
+		legacy:             "pkh(KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn)#yj0ctua6",
+		legacyUncompressed: "pkh(5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf)#vxzgs9na",
+		segWitCompat:       "sh(wpkh(KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn))#3xm2u094",
+		segWit:             "wpkh(KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn)#gul0776m",
+		taproot:            "tr(KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn)#efdxzarj",
+	},
+}
+
+func TestChecksum(t *testing.T) {
+	for _, test := range checksumTests {
+		result, err := descriptor.Checksum(test.input)
+
+		switch {
+		case err != nil:
+			t.Errorf("Checksum for %s FAILED: %v\n", test.input, err)
+		case result != test.output:
+			t.Errorf("Checksum for %s FAILED. Expected %s, got %s\n", test.input, test.output, result)
+		default:
+			t.Logf("Checksum passed: %s, %s\n", test.input, test.output)
+		}
+
+		if _, err := descriptor.VerifyChecksum(test.input+"#"+test.output, true); err != nil {
+			t.Errorf("VerifyChecksum for %s FAILED: %v\n", test.input, err)
+		}
+	}
+}
+
+func TestVerifyChecksumInvalid(t *testing.T) {
+	for _, test := range invalidChecksums {
+		if _, err := descriptor.VerifyChecksum(test, true); err == nil {
+			t.Errorf("VerifyChecksum for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("VerifyChecksum for %s failed: %v\n", test, err)
+		}
+	}
+
+	if _, err := descriptor.VerifyChecksum("raw(deadbeef)", true); err == nil {
+		t.Errorf("VerifyChecksum for missing checksum passed, should've failed: FAIL\n")
+	}
+}
+
+func TestGeneration(t *testing.T) {
+	for _, private := range []bool{false, true} {
+		testCases := publicTests
+		if private {
+			testCases = privateTests
+		}
+
+		for _, test := range testCases {
+			privateKey := keys.FromBigInt(test.input, chaincfg.MainNet)
+			results := []struct{ name, expected, result string }{
+				{"Legacy", test.legacy, descriptor.Legacy(&privateKey, private)},
+				{"LegacyUncompressed", test.legacyUncompressed, descriptor.LegacyUncompressed(&privateKey, private)},
+				{"SegWitCompat", test.segWitCompat, descriptor.SegWitCompat(&privateKey, private)},
+				{"SegWit", test.segWit, descriptor.SegWit(&privateKey, private)},
+				{"Taproot", test.taproot, descriptor.Taproot(&privateKey, private)},
+			}
+
+			for _, r := range results {
+				if r.result != r.expected {
+					t.Errorf("%s for %d FAILED. Expected %s, got %s\n", r.name, test.input, r.expected, r.result)
+				} else {
+					t.Logf("%s passed: %d, %s\n", r.name, test.input, r.expected)
+				}
+			}
+		}
+	}
+}
+
+type parseTestData struct {
+	input   string
+	index   uint32
+	script  string
+	address string
+}
+
+const (
+	bip86Account = "[73c5da0a/86'/0'/0']xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"
+	key1         = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	key2         = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+
+	key1Uncompressed = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+)
+
+var parseTests = []parseTestData{
+	{"pkh(" + key2 + ")#8fhd9pwu", 0, "76a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac", "1cMh228HTCiwS8ZsaakH8A8wze1JR5ZsP"},
+	{"pkh(5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf)", 0, "76a91491b24bf9f5288532960ac687abb035127b1d28a588ac", "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+	{"sh(wpkh(03fff97bd5755eeea420453a14355235d382f6472f8568a18b2f057a1460297556))", 0, "a914cc6ffbc0bf31af759451068f90ba7a0272b6b33287", "3LKyvRN6SmYXGBNn8fcQvYxW9MGKtwcinN"},
+	{"wsh(sortedmulti(1," + key2 + "," + key1 + "))", 0, "00206eb3ac1f460d34871c2b21e1ce02f0c056bcf558a6d4942052b1856a4fe54f6d", "bc1qd6e6c86xp56gw8pty8suuqhscpttea2c5m2fggzjkxzk5nl9faks5pehxu"},
+	{"wsh(multi(1," + key1 + "," + key2 + "))", 0, "00206eb3ac1f460d34871c2b21e1ce02f0c056bcf558a6d4942052b1856a4fe54f6d", "bc1qd6e6c86xp56gw8pty8suuqhscpttea2c5m2fggzjkxzk5nl9faks5pehxu"},
+	{"tr(a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56ac1c540c5bd)", 0, "512077aab6e066f8a7419c5ab714c12c67d25007ed55a43cadcacb4d7a970a093f11", "bc1pw74tdcrxlzn5r8z6ku2vztr86fgq0m245s72mjktf4afwzsf8ugs0gs8zu"},
+	{"tr(" + key1[2:] + ",{pk(" + key1[2:] + "),pk(" + key2[2:] + ")})", 0, "5120412fb5dfc2dbfc72485c0d003be886bcd0616857399f0168066283598d659e6b", "bc1pgyhmth7zm078yjzup5qrh6yxhngxz6zh8x0sz6qxv2p4nrt9ne4slaj9s9"},
+	{"tr(" + bip86Account + "/0/*)", 0, "5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	{"tr(" + bip86Account + "/0/*)#rg247h69", 1, "5120a82f29944d65b86ae6b5e5cc75e294ead6c59391a1edc5e016e3498c67fc7bbb", "bc1p4qhjn9zdvkux4e44uhx8tc55attvtyu358kutcqkudyccelu0was9fqzwh"},
+	{"addr(bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr)", 0, "5120a60869f0dbcf1dc659c9cecbaf8050135ea9e8cdc487053f1dc6880949dc684c", "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	{"addr(3LKyvRN6SmYXGBNn8fcQvYxW9MGKtwcinN)", 0, "a914cc6ffbc0bf31af759451068f90ba7a0272b6b33287", "3LKyvRN6SmYXGBNn8fcQvYxW9MGKtwcinN"},
+	{"raw(6a00)", 0, "6a00", ""},
+}
+
+var invalidDescriptors = []string{
+	"pkh(" + key1 + ")#aaaaaaaa",                                   // wrong checksum
+	"foo(" + key1 + ")",                                            // unknown function
+	"sh(sh(pkh(" + key1 + ")))",                                    // nested sh
+	"wpkh(5HpHagT65TZzG1PH3CSu63k8DbpvD8s5ip4nEB3kEsreAnchuDf)",    // uncompressed key in segwit
+	"pkh(" + key1[2:] + ")",                                        // x-only key outside tr
+	"multi(2," + key1 + ")",                                        // threshold above key count
+	"wsh(pkh(" + key1 + ")",                                        // unbalanced parentheses
+	"tr(" + bip86Account + "/0h/*)",                                // hardened derivation from xpub
+	"wpkh(" + key1 + "/0)",                                         // path on a non-extended key
+	"addr(bc1qinvalid)",                                            // invalid address
+	"sh(multi(1" + strings.Repeat(","+key1Uncompressed, 15) + "))", // 993-byte redeem script
+}
+
+func TestParse(t *testing.T) {
+	for _, test := range parseTests {
+		desc, err := descriptor.Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%s) FAILED: %v\n", test.input, err)
+			continue
+		}
+
+		script, err := desc.Script(test.index)
+		if err != nil || hex.EncodeToString(script) != test.script {
+			t.Errorf("Script for %s/%d FAILED. Expected %s, got %x (%v)\n", test.input, test.index, test.script, script, err)
+		}
+
+		address, _ := desc.Address(test.index, chaincfg.MainNet)
+		if address != test.address {
+			t.Errorf("Address for %s/%d FAILED. Expected %s, got %s\n", test.input, test.index, test.address, address)
+		} else {
+			t.Logf("Parse passed: %s/%d, %s\n", test.input, test.index, test.script)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range invalidDescriptors {
+		if _, err := descriptor.Parse(input); err == nil {
+			t.Errorf("Parse(%s) should have failed\n", input)
+		} else {
+			t.Logf("Parse correctly failed for %s: %v\n", input, err)
+		}
+	}
+}
+
+func TestTestnet(t *testing.T) {
+	tests := map[string]bool{
+		"tr(" + bip86Account + "/0/*)": false,
+		"wpkh(" + key1 + ")":           false,
+		"wsh(multi(1," + key1 + ",tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp/0/*))": true,
+	}
+
+	for input, expected := range tests {
+		desc, err := descriptor.Parse(input)
+		if err != nil || desc.Testnet() != expected {
+			t.Errorf("Testnet for %s FAILED. Expected %t (%v)\n", input, expected, err)
+		} else {
+			t.Logf("Testnet passed: %s, %t\n", input, expected)
+		}
+	}
+}
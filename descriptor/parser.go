@@ -0,0 +1,619 @@
+package descriptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/hd"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/taproot"
+)
+
+const maxMultisigKeys = 20
+
+// maxRedeemScriptSize is the consensus limit of a P2SH redeem script
+const maxRedeemScriptSize = 520
+
+// script contexts, which restrict the allowed functions and key types
+const (
+	contextTop = iota
+	contextSh
+	contextWsh
+	contextTap
+)
+
+var (
+	regexWifCompressed   = regexp.MustCompile(`^[KLc][1-9a-km-zA-HJ-NP-Z]+$`)
+	regexWifUncompressed = regexp.MustCompile(`^[59][1-9a-km-zA-HJ-NP-Z]+$`)
+)
+
+// Descriptor is a parsed output script descriptor (BIP380-386)
+type Descriptor struct {
+	body string
+	root *node
+}
+
+type node struct {
+	name      string
+	keys      []*keyExpr
+	threshold int
+	sub       *node
+	tree      *treeNode
+	data      []byte
+}
+
+type treeNode struct {
+	leaf        *node
+	left, right *treeNode
+}
+
+type keyExpr struct {
+	pubkey           []byte
+	extended         *hd.ExtendedKey
+	path             []uint32
+	wildcard         bool
+	hardenedWildcard bool
+	xonly            bool
+}
+
+// Parse parses a descriptor. The checksum is optional, but verified if present.
+func Parse(desc string) (*Descriptor, error) {
+	body, err := VerifyChecksum(strings.TrimSpace(desc), false)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseNode(body, contextTop)
+	if err != nil {
+		return nil, err
+	}
+	// the script size doesn't depend on the index, so building one checks the limits
+	if _, err := root.script(0); err != nil {
+		return nil, err
+	}
+	return &Descriptor{body, root}, nil
+}
+
+// String returns the descriptor with its checksum
+func (d *Descriptor) String() string {
+	desc, _ := AddChecksum(d.body)
+	return desc
+}
+
+// IsRange reports whether the descriptor has a /* wildcard key
+func (d *Descriptor) IsRange() bool {
+	return d.root.isRange()
+}
+
+// Testnet reports whether the descriptor has test network extended keys (tprv or tpub)
+func (d *Descriptor) Testnet() bool {
+	return d.root.testnet()
+}
+
+// Script returns the scriptPubKey for the given index (ignored for non-ranged descriptors)
+func (d *Descriptor) Script(index uint32) ([]byte, error) {
+	return d.root.script(index)
+}
+
+// Address returns the address for the given index, or an error if the script has no address form
+func (d *Descriptor) Address(index uint32, params *chaincfg.Params) (string, error) {
+	script, err := d.Script(index)
+	if err != nil {
+		return "", err
+	}
+	return address.FromScript(script, params)
+}
+
+func parseNode(s string, context int) (*node, error) {
+	name, args, err := splitCall(s)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &node{name: name}
+	switch name {
+	case "pk":
+		n.keys, err = parseKeys(args, 1, context)
+	case "pkh":
+		if context == contextTap {
+			return nil, errors.New("pkh() is not allowed in tapscript")
+		}
+		n.keys, err = parseKeys(args, 1, context)
+	case "wpkh":
+		if context != contextTop && context != contextSh {
+			return nil, errors.New("wpkh() is only allowed at top level or inside sh()")
+		}
+		n.keys, err = parseKeys(args, 1, contextWsh)
+	case "sh":
+		if context != contextTop {
+			return nil, errors.New("sh() is only allowed at top level")
+		}
+		n.sub, err = parseSingle(args, contextSh)
+	case "wsh":
+		if context != contextTop && context != contextSh {
+			return nil, errors.New("wsh() is only allowed at top level or inside sh()")
+		}
+		n.sub, err = parseSingle(args, contextWsh)
+	case "multi", "sortedmulti":
+		if context == contextTap {
+			return nil, fmt.Errorf("%s() is not allowed in tapscript", name)
+		}
+		err = n.parseMulti(args, context)
+	case "tr":
+		if context != contextTop {
+			return nil, errors.New("tr() is only allowed at top level")
+		}
+		err = n.parseTaproot(args)
+	case "addr":
+		if context != contextTop || len(args) != 1 {
+			return nil, errors.New("addr() takes one address, at top level")
+		}
+		var decoded *address.Address
+		if decoded, err = address.Decode(args[0]); err == nil {
+			n.data = decoded.Script
+		}
+	case "raw":
+		if context != contextTop || len(args) != 1 {
+			return nil, errors.New("raw() takes one script, at top level")
+		}
+		if n.data, err = hex.DecodeString(args[0]); err != nil {
+			err = fmt.Errorf("invalid raw script: %s", args[0])
+		}
+	default:
+		return nil, fmt.Errorf("unknown descriptor function: %s", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func parseSingle(args []string, context int) (*node, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	return parseNode(args[0], context)
+}
+
+func parseKeys(args []string, count, context int) ([]*keyExpr, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("expected %d key(s), got %d", count, len(args))
+	}
+
+	var parsed []*keyExpr
+	for _, arg := range args {
+		key, err := parseKey(arg, context)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, key)
+	}
+	return parsed, nil
+}
+
+func (n *node) parseMulti(args []string, context int) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%s() needs a threshold and at least one key", n.name)
+	}
+
+	threshold, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid multisig threshold: %s", args[0])
+	}
+
+	maxKeys := maxMultisigKeys
+	switch context {
+	case contextTop:
+		maxKeys = 3
+	case contextSh:
+		maxKeys = 15
+	}
+
+	keyCount := len(args) - 1
+	if keyCount > maxKeys {
+		return fmt.Errorf("too many keys for %s(): %d > %d", n.name, keyCount, maxKeys)
+	}
+	if threshold < 1 || threshold > keyCount {
+		return fmt.Errorf("invalid multisig threshold: %d of %d", threshold, keyCount)
+	}
+
+	n.threshold = threshold
+	n.keys, err = parseKeys(args[1:], keyCount, context)
+	return err
+}
+
+func (n *node) parseTaproot(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("tr() takes a key and an optional script tree, got %d arguments", len(args))
+	}
+
+	var err error
+	if n.keys, err = parseKeys(args[:1], 1, contextTap); err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		n.tree, err = parseTree(args[1])
+	}
+	return err
+}
+
+func parseTree(s string) (*treeNode, error) {
+	if !strings.HasPrefix(s, "{") {
+		leaf, err := parseNode(s, contextTap)
+		if err != nil {
+			return nil, err
+		}
+		return &treeNode{leaf: leaf}, nil
+	}
+
+	if !strings.HasSuffix(s, "}") {
+		return nil, fmt.Errorf("unbalanced braces in script tree: %s", s)
+	}
+
+	branches, err := splitArgs(s[1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) != 2 {
+		return nil, fmt.Errorf("script tree branch needs 2 children, got %d", len(branches))
+	}
+
+	left, err := parseTree(branches[0])
+	if err != nil {
+		return nil, err
+	}
+	right, err := parseTree(branches[1])
+	if err != nil {
+		return nil, err
+	}
+	return &treeNode{left: left, right: right}, nil
+}
+
+func parseKey(s string, context int) (*keyExpr, error) {
+	key := &keyExpr{xonly: context == contextTap}
+
+	if strings.HasPrefix(s, "[") {
+		end := strings.Index(s, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated key origin: %s", s)
+		}
+		if err := validateOrigin(s[1:end]); err != nil {
+			return nil, err
+		}
+		s = s[end+1:]
+	}
+
+	elements := strings.Split(s, "/")
+	encoded := elements[0]
+
+	switch {
+	case regexHexKey(encoded):
+		pubkey, _ := hex.DecodeString(encoded)
+		if err := key.setPubkey(pubkey, context); err != nil {
+			return nil, err
+		}
+	case regexWifCompressed.MatchString(encoded), regexWifUncompressed.MatchString(encoded):
+		params := chaincfg.MainNet
+		if strings.ContainsAny(encoded[:1], "c9") {
+			params = chaincfg.TestNet3
+		}
+		number, err := base58.Decode(encoded, params.PrivateKeyID)
+		if err != nil {
+			return nil, err
+		}
+
+		privateKey := keys.FromBigInt(number, params)
+		pubkey := privateKey.PublicKey()
+		if regexWifUncompressed.MatchString(encoded) {
+			pubkey = privateKey.PublicKeyUncompressed()
+		}
+		if err := key.setPubkey(pubkey, context); err != nil {
+			return nil, err
+		}
+	default:
+		extended, err := hd.Parse(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", encoded, err)
+		}
+		key.extended = extended
+		if err := key.parsePath(elements[1:]); err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+
+	if len(elements) > 1 {
+		return nil, fmt.Errorf("derivation path on a non-extended key: %s", s)
+	}
+	return key, nil
+}
+
+func regexHexKey(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil && (len(s) == 64 || len(s) == 66 || len(s) == 130)
+}
+
+func (key *keyExpr) setPubkey(pubkey []byte, context int) error {
+	switch {
+	case len(pubkey) == 32 && context != contextTap:
+		return fmt.Errorf("x-only key only allowed in tr(): %x", pubkey)
+	case len(pubkey) == 65 && (context == contextWsh || context == contextTap):
+		return fmt.Errorf("uncompressed key not allowed in segwit: %x", pubkey)
+	case len(pubkey) == 32:
+		if _, _, err := taproot.TweakPublicKey(pubkey, nil); err != nil {
+			return err
+		}
+	case !validPubkey(pubkey):
+		return fmt.Errorf("invalid public key: %x", pubkey)
+	}
+
+	key.pubkey = pubkey
+	return nil
+}
+
+func (key *keyExpr) parsePath(elements []string) error {
+	if len(elements) > 0 {
+		last := elements[len(elements)-1]
+		switch last {
+		case "*":
+			key.wildcard = true
+		case "*'", "*h", "*H":
+			key.wildcard, key.hardenedWildcard = true, true
+		}
+		if key.wildcard {
+			elements = elements[:len(elements)-1]
+		}
+	}
+
+	path, err := hd.ParsePath(strings.Join(elements, "/"))
+	if err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if element == "" || strings.Contains(element, "*") {
+			return fmt.Errorf("invalid path element: %s", element)
+		}
+	}
+
+	if !key.extended.IsPrivate() {
+		for _, index := range path {
+			if index >= hd.HardenedOffset {
+				return errors.New("hardened derivation requires an extended private key")
+			}
+		}
+		if key.hardenedWildcard {
+			return errors.New("hardened derivation requires an extended private key")
+		}
+	}
+
+	key.path = path
+	return nil
+}
+
+func validateOrigin(origin string) error {
+	elements := strings.SplitN(origin, "/", 2)
+	if fp, err := hex.DecodeString(elements[0]); err != nil || len(fp) != 4 {
+		return fmt.Errorf("invalid key origin fingerprint: %s", elements[0])
+	}
+	if len(elements) == 2 {
+		if _, err := hd.ParsePath(elements[1]); err != nil || elements[1] == "" {
+			return fmt.Errorf("invalid key origin path: %s", elements[1])
+		}
+	}
+	return nil
+}
+
+// derive returns the serialized public key for the given index
+func (key *keyExpr) derive(index uint32) ([]byte, error) {
+	pubkey := key.pubkey
+	if key.extended != nil {
+		child, err := key.extended.Derive(hd.FormatPath(key.path))
+		if err != nil {
+			return nil, err
+		}
+
+		if key.wildcard {
+			if key.hardenedWildcard {
+				index += hd.HardenedOffset
+			}
+			if child, err = child.Child(index); err != nil {
+				return nil, err
+			}
+		}
+		pubkey = child.PublicKey()
+	}
+
+	if key.xonly && len(pubkey) == 33 {
+		return pubkey[1:], nil
+	}
+	return pubkey, nil
+}
+
+func (n *node) isRange() bool {
+	for _, key := range n.keys {
+		if key.wildcard {
+			return true
+		}
+	}
+	if n.sub != nil && n.sub.isRange() {
+		return true
+	}
+	return n.tree != nil && n.tree.isRange()
+}
+
+func (t *treeNode) isRange() bool {
+	if t.leaf != nil {
+		return t.leaf.isRange()
+	}
+	return t.left.isRange() || t.right.isRange()
+}
+
+func (n *node) testnet() bool {
+	for _, key := range n.keys {
+		if key.extended != nil && key.extended.Params().Testnet {
+			return true
+		}
+	}
+	if n.sub != nil && n.sub.testnet() {
+		return true
+	}
+	return n.tree != nil && n.tree.testnet()
+}
+
+func (t *treeNode) testnet() bool {
+	if t.leaf != nil {
+		return t.leaf.testnet()
+	}
+	return t.left.testnet() || t.right.testnet()
+}
+
+func (n *node) derivedKeys(index uint32) ([][]byte, error) {
+	var pubkeys [][]byte
+	for _, key := range n.keys {
+		pubkey, err := key.derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+	return pubkeys, nil
+}
+
+func (n *node) script(index uint32) ([]byte, error) {
+	pubkeys, err := n.derivedKeys(index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.name {
+	case "pk":
+		return script.P2PK(pubkeys[0]), nil
+	case "pkh":
+		return script.P2PKH(crypto.Hash160(pubkeys[0])), nil
+	case "wpkh":
+		return script.WitnessProgram(0, crypto.Hash160(pubkeys[0])), nil
+	case "sh":
+		redeem, err := n.sub.script(index)
+		if err != nil {
+			return nil, err
+		}
+		if len(redeem) > maxRedeemScriptSize {
+			return nil, fmt.Errorf("redeem script too large for P2SH: %d bytes > %d", len(redeem), maxRedeemScriptSize)
+		}
+		return script.P2SH(crypto.Hash160(redeem)), nil
+	case "wsh":
+		witnessScript, err := n.sub.script(index)
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(witnessScript)
+		return script.WitnessProgram(0, hash[:]), nil
+	case "multi", "sortedmulti":
+		if n.name == "sortedmulti" {
+			sort.Slice(pubkeys, func(i, j int) bool {
+				return bytes.Compare(pubkeys[i], pubkeys[j]) < 0
+			})
+		}
+		return script.Multisig(n.threshold, pubkeys), nil
+	case "tr":
+		var merkleRoot []byte
+		if n.tree != nil {
+			if merkleRoot, err = n.tree.hash(index); err != nil {
+				return nil, err
+			}
+		}
+		outputKey, _, err := taproot.TweakPublicKey(pubkeys[0], merkleRoot)
+		if err != nil {
+			return nil, err
+		}
+		return script.WitnessProgram(1, outputKey), nil
+	default:
+		return n.data, nil
+	}
+}
+
+func (t *treeNode) hash(index uint32) ([]byte, error) {
+	if t.leaf != nil {
+		script, err := t.leaf.script(index)
+		if err != nil {
+			return nil, err
+		}
+		return taproot.NewLeaf(script).Hash(), nil
+	}
+
+	left, err := t.left.hash(index)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.right.hash(index)
+	if err != nil {
+		return nil, err
+	}
+	return taproot.BranchHash(left, right), nil
+}
+
+// splitCall splits "name(arg1,arg2)" into its name and top-level arguments
+func splitCall(s string) (string, []string, error) {
+	open := strings.Index(s, "(")
+	if open < 1 || !strings.HasSuffix(s, ")") {
+		return "", nil, fmt.Errorf("invalid descriptor expression: %s", s)
+	}
+
+	args, err := splitArgs(s[open+1 : len(s)-1])
+	if err != nil {
+		return "", nil, err
+	}
+	return s[:open], args, nil
+}
+
+// splitArgs splits on commas that are not nested in (), [] or {}
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced brackets: %s", s)
+			}
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets: %s", s)
+	}
+	return append(args, s[start:]), nil
+}
+
+func validPubkey(pubkey []byte) bool {
+	if len(pubkey) == 33 && pubkey[0] != 0x02 && pubkey[0] != 0x03 {
+		return false
+	}
+	if len(pubkey) == 65 && pubkey[0] != 0x04 {
+		return false
+	}
+	_, err := secp256k1.ParsePubKey(pubkey)
+	return err == nil
+}
@@ -5,6 +5,11 @@ go 1.20
 require (
 	github.com/decred/dcrd/dcrec/secp256k1 v1.0.4
 	golang.org/x/crypto v0.11.0
+	golang.org/x/term v0.10.0
+	golang.org/x/text v0.13.0
 )
 
-require github.com/decred/dcrd/dcrec/secp256k1/v2 v2.0.0 // indirect
+require (
+	github.com/decred/dcrd/dcrec/secp256k1/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+)
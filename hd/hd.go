@@ -0,0 +1,348 @@
+package hd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+)
+
+// HardenedOffset is the index of the first hardened child
+const HardenedOffset uint32 = 0x80000000
+
+const serializedLength = 78
+
+var masterKey = []byte("Bitcoin seed")
+
+// ExtendedKey is a BIP32 extended private or public key
+type ExtendedKey struct {
+	key       []byte // 32-byte private key or 33-byte compressed public key
+	chainCode []byte
+	depth     byte
+	parentFP  []byte
+	childNum  uint32
+	private   bool
+	purpose   uint32 // SLIP-132 purpose of the parsed prefix, serialized back with it
+	params    *chaincfg.Params
+}
+
+// NewMaster creates the master extended private key from a seed for the given network.
+func NewMaster(seed []byte, params *chaincfg.Params) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed length: %d bytes", len(seed))
+	}
+
+	mac := hmac.New(sha512.New, masterKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	if !validPrivateKey(sum[:32]) {
+		return nil, errors.New("invalid master key, use another seed")
+	}
+
+	return &ExtendedKey{
+		key:       sum[:32],
+		chainCode: sum[32:],
+		parentFP:  make([]byte, 4),
+		private:   true,
+		params:    params,
+	}, nil
+}
+
+// Parse parses a serialized extended key (xprv, xpub, tprv, tpub or their SLIP-132 variants).
+func Parse(s string) (*ExtendedKey, error) {
+	prefix, data, err := base58.CheckDecode(s, 4)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) != serializedLength-4 {
+		return nil, fmt.Errorf("invalid extended key length: %d", len(data)+4)
+	}
+
+	k := &ExtendedKey{
+		depth:     data[0],
+		parentFP:  data[1:5],
+		childNum:  binary.BigEndian.Uint32(data[5:9]),
+		chainCode: data[9:41],
+	}
+
+	version, private, ok := findVersion(prefix)
+	if !ok {
+		return nil, fmt.Errorf("unknown extended key version: %x", prefix)
+	}
+	k.private, k.purpose, k.params = private, version.purpose, chaincfg.MainNet
+	if version.testnet {
+		k.params = chaincfg.TestNet3
+	}
+
+	if k.depth == 0 && (!bytes.Equal(k.parentFP, []byte{0, 0, 0, 0}) || k.childNum != 0) {
+		return nil, errors.New("invalid extended key: depth 0 with non-zero parent fingerprint or index")
+	}
+
+	if k.private {
+		if data[41] != 0x00 {
+			return nil, fmt.Errorf("invalid private key prefix: %02x", data[41])
+		}
+		if !validPrivateKey(data[42:]) {
+			return nil, errors.New("invalid private key: out of range")
+		}
+		k.key = data[42:]
+	} else {
+		if _, err := secp256k1.ParsePubKey(data[41:]); err != nil || data[41] == 0x04 {
+			return nil, errors.New("invalid public key")
+		}
+		k.key = data[41:]
+	}
+
+	return k, nil
+}
+
+// String returns the Base58Check serialization of the key, with the prefix it was parsed
+// with (like yprv or zpub), or xprv, xpub, tprv or tpub.
+func (k *ExtendedKey) String() string {
+	return k.serialize(lookupVersion(k.params.Testnet, k.purpose))
+}
+
+func (k *ExtendedKey) serialize(version keyVersion) string {
+	prefix := version.public
+	if k.private {
+		prefix = version.private
+	}
+
+	data := make([]byte, 0, serializedLength-4)
+	data = append(data, k.depth)
+	data = append(data, k.parentFP...)
+	data = binary.BigEndian.AppendUint32(data, k.childNum)
+	data = append(data, k.chainCode...)
+	if k.private {
+		data = append(data, 0x00)
+	}
+	data = append(data, k.key...)
+
+	return base58.CheckEncode(prefix, data)
+}
+
+// IsPrivate reports whether the key is an extended private key
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.private
+}
+
+// Params returns the network of the key. Parsed keys default to mainnet or testnet3.
+func (k *ExtendedKey) Params() *chaincfg.Params {
+	return k.params
+}
+
+// WithParams returns the key on another network sharing its serialization prefixes,
+// like regtest or signet for a tprv.
+func (k *ExtendedKey) WithParams(params *chaincfg.Params) (*ExtendedKey, error) {
+	if params.Testnet != k.params.Testnet {
+		return nil, fmt.Errorf("extended key is not valid on %s", params.Name)
+	}
+
+	key := *k
+	key.params = params
+	return &key, nil
+}
+
+// Depth returns the depth of the key (0 for the master key)
+func (k *ExtendedKey) Depth() byte {
+	return k.depth
+}
+
+// ParentFingerprint returns the fingerprint of the parent key
+func (k *ExtendedKey) ParentFingerprint() []byte {
+	return k.parentFP
+}
+
+// ChildNumber returns the index of the key in its parent
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNum
+}
+
+// ChainCode returns the chain code
+func (k *ExtendedKey) ChainCode() []byte {
+	return k.chainCode
+}
+
+// PublicKey returns the compressed public key
+func (k *ExtendedKey) PublicKey() []byte {
+	if !k.private {
+		return k.key
+	}
+
+	_, pubkey := secp256k1.PrivKeyFromBytes(k.key)
+	return pubkey.SerializeCompressed()
+}
+
+// Fingerprint returns the first 4 bytes of the public key hash
+func (k *ExtendedKey) Fingerprint() []byte {
+	return crypto.Hash160(k.PublicKey())[:4]
+}
+
+// PrivateKey returns the keys.PrivateKey of an extended private key.
+func (k *ExtendedKey) PrivateKey() (keys.PrivateKey, error) {
+	if !k.private {
+		return keys.PrivateKey{}, errors.New("not an extended private key")
+	}
+
+	return keys.FromBigInt(new(big.Int).SetBytes(k.key), k.params), nil
+}
+
+// Neuter returns the extended public key.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.private {
+		return k
+	}
+
+	return &ExtendedKey{
+		key:       k.PublicKey(),
+		chainCode: k.chainCode,
+		depth:     k.depth,
+		parentFP:  k.parentFP,
+		childNum:  k.childNum,
+		purpose:   k.purpose,
+		params:    k.params,
+	}
+}
+
+// Child derives the child key at the given index (hardened if index >= HardenedOffset).
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if k.depth == 0xff {
+		return nil, errors.New("maximum derivation depth reached")
+	}
+
+	hardened := index >= HardenedOffset
+	if hardened && !k.private {
+		return nil, errors.New("cannot derive a hardened child from a public key")
+	}
+
+	var data []byte
+	if hardened {
+		data = append([]byte{0x00}, k.key...)
+	} else {
+		data = k.PublicKey()
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	il, chainCode := sum[:32], sum[32:]
+
+	curve := secp256k1.S256()
+	tweak := new(big.Int).SetBytes(il)
+	if tweak.Cmp(curve.Params().N) >= 0 {
+		return nil, fmt.Errorf("invalid child %d, use the next index", index)
+	}
+
+	child := &ExtendedKey{
+		chainCode: chainCode,
+		depth:     k.depth + 1,
+		parentFP:  k.Fingerprint(),
+		childNum:  index,
+		private:   k.private,
+		purpose:   k.purpose,
+		params:    k.params,
+	}
+
+	if k.private {
+		childKey := tweak.Add(tweak, new(big.Int).SetBytes(k.key))
+		childKey.Mod(childKey, curve.Params().N)
+		if childKey.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child %d, use the next index", index)
+		}
+
+		child.key = make([]byte, 32)
+		childKey.FillBytes(child.key)
+	} else {
+		parent, _ := secp256k1.ParsePubKey(k.key)
+		tx, ty := curve.ScalarBaseMult(il)
+		x, y := curve.Add(tx, ty, parent.X, parent.Y)
+		if x.Sign() == 0 && y.Sign() == 0 {
+			return nil, fmt.Errorf("invalid child %d, use the next index", index)
+		}
+
+		child.key = secp256k1.NewPublicKey(x, y).SerializeCompressed()
+	}
+
+	return child, nil
+}
+
+// Derive derives the descendant key at the given path, like m/84'/0'/0'/0/5.
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	child := k
+	for _, index := range indexes {
+		if child, err = child.Child(index); err != nil {
+			return nil, err
+		}
+	}
+
+	return child, nil
+}
+
+// ParsePath parses a derivation path into child indexes.
+// Hardened indexes are marked with ', h or H. The leading "m/" is optional.
+func ParsePath(path string) ([]uint32, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "m")
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil, nil
+	}
+
+	var indexes []uint32
+	for _, element := range strings.Split(path, "/") {
+		hardened := false
+		if strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h") || strings.HasSuffix(element, "H") {
+			hardened = true
+			element = element[:len(element)-1]
+		}
+
+		index, err := strconv.ParseUint(element, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("invalid path element: %s", element)
+		}
+
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+
+	return indexes, nil
+}
+
+// FormatPath formats child indexes as a derivation path, like m/84'/0'/0'/0/5.
+func FormatPath(indexes []uint32) string {
+	path := "m"
+	for _, index := range indexes {
+		if index >= HardenedOffset {
+			path += fmt.Sprintf("/%d'", index-HardenedOffset)
+		} else {
+			path += fmt.Sprintf("/%d", index)
+		}
+	}
+	return path
+}
+
+func validPrivateKey(key []byte) bool {
+	number := new(big.Int).SetBytes(key)
+	return number.Sign() > 0 && number.Cmp(secp256k1.S256().Params().N) < 0
+}
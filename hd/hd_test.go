@@ -0,0 +1,251 @@
+package hd_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/hd"
+)
+
+type derivationTestData struct {
+	path string
+	xpub string
+	xprv string
+}
+
+type vectorTestData struct {
+	seed        string
+	derivations []derivationTestData
+}
+
+// BIP32 test vectors 1 and 2
+var vectorTests = []vectorTestData{
+	{
+		seed: "000102030405060708090a0b0c0d0e0f",
+		derivations: []derivationTestData{
+			{"m", "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8", "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"},
+			{"m/0H", "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", "xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7"},
+			{"m/0H/1", "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", "xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs"},
+			{"m/0H/1/2H", "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5", "xprv9z4pot5VBttmtdRTWfWQmoH1taj2axGVzFqSb8C9xaxKymcFzXBDptWmT7FwuEzG3ryjH4ktypQSAewRiNMjANTtpgP4mLTj34bhnZX7UiM"},
+			{"m/0H/1/2H/2", "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV", "xprvA2JDeKCSNNZky6uBCviVfJSKyQ1mDYahRjijr5idH2WwLsEd4Hsb2Tyh8RfQMuPh7f7RtyzTtdrbdqqsunu5Mm3wDvUAKRHSC34sJ7in334"},
+			{"m/0H/1/2H/2/1000000000", "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", "xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76"},
+		},
+	},
+	{
+		seed: "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542",
+		derivations: []derivationTestData{
+			{"m", "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB", "xprv9s21ZrQH143K31xYSDQpPDxsXRTUcvj2iNHm5NUtrGiGG5e2DtALGdso3pGz6ssrdK4PFmM8NSpSBHNqPqm55Qn3LqFtT2emdEXVYsCzC2U"},
+			{"m/0", "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH", "xprv9vHkqa6EV4sPZHYqZznhT2NPtPCjKuDKGY38FBWLvgaDx45zo9WQRUT3dKYnjwih2yJD9mkrocEZXo1ex8G81dwSM1fwqWpWkeS3v86pgKt"},
+			{"m/0/2147483647H", "xpub6ASAVgeehLbnwdqV6UKMHVzgqAG8Gr6riv3Fxxpj8ksbH9ebxaEyBLZ85ySDhKiLDBrQSARLq1uNRts8RuJiHjaDMBU4Zn9h8LZNnBC5y4a", "xprv9wSp6B7kry3Vj9m1zSnLvN3xH8RdsPP1Mh7fAaR7aRLcQMKTR2vidYEeEg2mUCTAwCd6vnxVrcjfy2kRgVsFawNzmjuHc2YmYRmagcEPdU9"},
+			{"m/0/2147483647H/1", "xpub6DF8uhdarytz3FWdA8TvFSvvAh8dP3283MY7p2V4SeE2wyWmG5mg5EwVvmdMVCQcoNJxGoWaU9DCWh89LojfZ537wTfunKau47EL2dhHKon", "xprv9zFnWC6h2cLgpmSA46vutJzBcfJ8yaJGg8cX1e5StJh45BBciYTRXSd25UEPVuesF9yog62tGAQtHjXajPPdbRCHuWS6T8XA2ECKADdw4Ef"},
+			{"m/0/2147483647H/1/2147483646H", "xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL", "xprvA1RpRA33e1JQ7ifknakTFpgNXPmW2YvmhqLQYMmrj4xJXXWYpDPS3xz7iAxn8L39njGVyuoseXzU6rcxFLJ8HFsTjSyQbLYnMpCqE2VbFWc"},
+			{"m/0/2147483647H/1/2147483646H/2", "xpub6FnCn6nSzZAw5Tw7cgR9bi15UV96gLZhjDstkXXxvCLsUXBGXPdSnLFbdpq8p9HmGsApME5hQTZ3emM2rnY5agb9rXpVGyy3bdW6EEgAtqt", "xprvA2nrNbFZABcdryreWet9Ea4LvTJcGsqrMzxHx98MMrotbir7yrKCEXw7nadnHM8Dq38EGfSh6dqA9QWTyefMLEcBYJUuekgW4BYPJcr9E7j"},
+		},
+	},
+}
+
+var invalidKeys = []string{
+	// pubkey version / prvkey mismatch
+	"xpub661MyMwAqRbcEYS8w7XLSVeEsBXy79zSzH1J8vCdxAZningWLdN3zgtU6LBpB85b3D2yc8sfvZU521AAwdZafEz7mnzBBsz4wKY5fTtTQBm",
+	// invalid prvkey prefix 01
+	"xprv9s21ZrQH143K24Mfq5zL5MhWK9hUhhGbd45hLXo2Pq2oqzMMo63oStZzFAzHGBP2UuGCqWLTAPLcMtD9y5gkZ6Eq3Rjuahrv17fEQ3Qen6J",
+	// zero depth with non-zero parent fingerprint
+	"xprv9s2SPatNQ9Vc6GTbVMFPFo7jsaZySyzk7L8n2uqKXJen3KUmvQNTuLh3fhZMBoG3G4ZW1N2kZuHEPY53qmbZzCHshoQnNf4GvELZfqTUrcv",
+	// unknown extended key version
+	"DMwo58pR1QLEFihHiXPVykYB6fJmsTeHvyTp7hRThAtCX8CvYzgPcn8XnmdfHGMQzT7ayAmfo4z3gY5KfbrZWZ6St24UVf2Qgo6oujFktLHdHY4",
+	// bad checksum
+	"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHL",
+}
+
+func TestDerivation(t *testing.T) {
+	for _, vector := range vectorTests {
+		seed, _ := hex.DecodeString(vector.seed)
+		master, err := hd.NewMaster(seed, chaincfg.MainNet)
+		if err != nil {
+			t.Fatalf("NewMaster for %s FAILED: %v\n", vector.seed, err)
+		}
+
+		for _, test := range vector.derivations {
+			child, err := master.Derive(test.path)
+			if err != nil {
+				t.Errorf("Derive for %s FAILED: %v\n", test.path, err)
+				continue
+			}
+
+			if xprv := child.String(); xprv != test.xprv {
+				t.Errorf("Derive for %s FAILED. Expected %s, got %s\n", test.path, test.xprv, xprv)
+			} else {
+				t.Logf("Derive passed: %s, %s\n", test.path, test.xprv)
+			}
+
+			if xpub := child.Neuter().String(); xpub != test.xpub {
+				t.Errorf("Neuter for %s FAILED. Expected %s, got %s\n", test.path, test.xpub, xpub)
+			} else {
+				t.Logf("Neuter passed: %s, %s\n", test.path, test.xpub)
+			}
+		}
+	}
+}
+
+func TestPublicDerivation(t *testing.T) {
+	for _, vector := range vectorTests {
+		for i := 1; i < len(vector.derivations); i++ {
+			parent, child := vector.derivations[i-1], vector.derivations[i]
+			indexes, _ := hd.ParsePath(child.path)
+			index := indexes[len(indexes)-1]
+			if index >= hd.HardenedOffset {
+				continue
+			}
+
+			xpub, err := hd.Parse(parent.xpub)
+			if err != nil {
+				t.Fatalf("Parse for %s FAILED: %v\n", parent.xpub, err)
+			}
+
+			derived, err := xpub.Child(index)
+			switch {
+			case err != nil:
+				t.Errorf("Child for %s FAILED: %v\n", child.path, err)
+			case derived.String() != child.xpub:
+				t.Errorf("Child for %s FAILED. Expected %s, got %s\n", child.path, child.xpub, derived)
+			default:
+				t.Logf("Child passed: %s, %s\n", child.path, child.xpub)
+			}
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	for _, vector := range vectorTests {
+		for _, test := range vector.derivations {
+			for _, s := range []string{test.xprv, test.xpub} {
+				key, err := hd.Parse(s)
+				switch {
+				case err != nil:
+					t.Errorf("Parse for %s FAILED: %v\n", s, err)
+				case key.String() != s:
+					t.Errorf("Parse for %s FAILED. Got %s back\n", s, key)
+				default:
+					t.Logf("Parse passed: %s\n", s)
+				}
+			}
+		}
+	}
+}
+
+func TestHardenedFromPublic(t *testing.T) {
+	xpub, _ := hd.Parse(vectorTests[0].derivations[0].xpub)
+	if _, err := xpub.Derive("m/0'"); err == nil {
+		t.Errorf("Derive hardened from xpub passed, should've failed: FAIL\n")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, test := range invalidKeys {
+		if _, err := hd.Parse(test); err == nil {
+			t.Errorf("Parse for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("Parse for %s failed: %v\n", test, err)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	indexes, err := hd.ParsePath("m/84'/0h/0H/0/5")
+	if err != nil {
+		t.Fatalf("ParsePath FAILED: %v\n", err)
+	}
+
+	if path := hd.FormatPath(indexes); path != "m/84'/0'/0'/0/5" {
+		t.Errorf("FormatPath FAILED. Expected m/84'/0'/0'/0/5, got %s\n", path)
+	}
+
+	for _, path := range []string{"m/a", "m/0/", "m/2147483648", "m/-1"} {
+		if _, err := hd.ParsePath(path); err == nil {
+			t.Errorf("ParsePath for %s passed, should've failed: FAIL\n", path)
+		}
+	}
+}
+
+type accountTestData struct {
+	purpose uint32
+	params  *chaincfg.Params
+	path    string
+	xpub    string
+}
+
+// BIP44, BIP49, BIP84 and BIP86 account keys for "abandon ... about"
+const accountSeed = "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+
+var accountTests = []accountTestData{
+	{hd.PurposeLegacy, chaincfg.MainNet, "m/44'/0'/0'", "xpub6BosfCnifzxcFwrSzQiqu2DBVTshkCXacvNsWGYJVVhhawA7d4R5WSWGFNbi8Aw6ZRc1brxMyWMzG3DSSSSoekkudhUd9yLb6qx39T9nMdj"},
+	{hd.PurposeNestedSegwit, chaincfg.MainNet, "m/49'/0'/0'", "ypub6Ww3ibxVfGzLrAH1PNcjyAWenMTbbAosGNB6VvmSEgytSER9azLDWCxoJwW7Ke7icmizBMXrzBx9979FfaHxHcrArf3zbeJJJUZPf663zsP"},
+	{hd.PurposeNativeSegwit, chaincfg.MainNet, "m/84'/0'/0'", "zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs"},
+	{hd.PurposeTaproot, chaincfg.MainNet, "m/86'/0'/0'", "xpub6BgBgsespWvERF3LHQu6CnqdvfEvtMcQjYrcRzx53QJjSxarj2afYWcLteoGVky7D3UKDP9QyrLprQ3VCECoY49yfdDEHGCtMMj92pReUsQ"},
+	{hd.PurposeNestedSegwit, chaincfg.TestNet3, "m/49'/1'/0'", "upub5EFU65HtV5TeiSHmZZm7FUffBGy8UKeqp7vw43jYbvZPpoVsgU93oac7Wk3u6moKegAEWtGNF8DehrnHtv21XXEMYRUocHqguyjknFHYfgY"},
+	{hd.PurposeNativeSegwit, chaincfg.TestNet3, "m/84'/1'/0'", "vpub5Y6cjg78GGuNLsaPhmYsiw4gYX3HoQiRBiSwDaBXKUafCt9bNwWQiitDk5VZ5BVxYnQdwoTyXSs2JHRPAgjAvtbBrf8ZhDYe2jWAqvZVnsc"},
+}
+
+func TestAccount(t *testing.T) {
+	seed, _ := hex.DecodeString(accountSeed)
+	for _, test := range accountTests {
+		master, _ := hd.NewMaster(seed, test.params)
+
+		if path := hd.AccountPath(test.purpose, test.params, 0); path != test.path {
+			t.Errorf("AccountPath for %d FAILED. Expected %s, got %s\n", test.purpose, test.path, path)
+		}
+
+		account, err := master.Account(test.purpose, 0)
+		if err != nil {
+			t.Errorf("Account for %s FAILED: %v\n", test.path, err)
+			continue
+		}
+
+		if xpub := account.Neuter().StringSLIP132(test.purpose); xpub != test.xpub {
+			t.Errorf("StringSLIP132 for %s FAILED. Expected %s, got %s\n", test.path, test.xpub, xpub)
+		} else {
+			t.Logf("StringSLIP132 passed: %s, %s\n", test.path, test.xpub)
+		}
+
+		parsed, err := hd.Parse(test.xpub)
+		switch {
+		case err != nil:
+			t.Errorf("Parse for %s FAILED: %v\n", test.xpub, err)
+		case parsed.String() != test.xpub:
+			t.Errorf("Parse for %s FAILED. Got %s back\n", test.xpub, parsed)
+		case !bytes.Equal(parsed.PublicKey(), account.PublicKey()):
+			t.Errorf("Parse for %s FAILED. Expected public key %x, got %x\n", test.xpub, account.PublicKey(), parsed.PublicKey())
+		default:
+			t.Logf("Parse passed: %s\n", test.xpub)
+		}
+
+		// derived keys keep the SLIP-132 prefix of the parsed key
+		child, _ := parsed.Child(0)
+		if prefix := child.String()[:4]; prefix != test.xpub[:4] {
+			t.Errorf("Child of %s FAILED. Expected %s prefix, got %s\n", test.xpub, test.xpub[:4], child)
+		}
+	}
+}
+
+func TestWithParams(t *testing.T) {
+	tprv, _ := hd.Parse("tprv8ZgxMBicQKsPd7Uf69XL1XwhmjHopUGep8GuEiJDZmbQz6o58LninorQAfcKZWARbtRtfnLcJ5MQ2AtHcQJCCRUcMRvmDUjyEmNUWwx8UbK")
+	if tprv.Params() != chaincfg.TestNet3 {
+		t.Errorf("Params for tprv FAILED. Expected testnet3, got %s\n", tprv.Params().Name)
+	}
+
+	regtest, err := tprv.WithParams(chaincfg.RegTest)
+	switch {
+	case err != nil:
+		t.Errorf("WithParams regtest FAILED: %v\n", err)
+	case regtest.Params() != chaincfg.RegTest || regtest.String() != tprv.String():
+		t.Errorf("WithParams regtest FAILED. Got %s, %s\n", regtest.Params().Name, regtest)
+	default:
+		t.Logf("WithParams passed: regtest, %s\n", regtest)
+	}
+
+	if _, err := tprv.WithParams(chaincfg.MainNet); err == nil {
+		t.Errorf("WithParams mainnet for a tprv passed, should've failed: FAIL\n")
+	}
+}
@@ -0,0 +1,78 @@
+package hd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ottosch/pick-private/chaincfg"
+)
+
+// BIP44, BIP49, BIP84 and BIP86 purposes
+const (
+	PurposeLegacy       uint32 = 44
+	PurposeNestedSegwit uint32 = 49
+	PurposeNativeSegwit uint32 = 84
+	PurposeTaproot      uint32 = 86
+)
+
+// Purposes lists the standard single-key account purposes
+var Purposes = []uint32{PurposeLegacy, PurposeNestedSegwit, PurposeNativeSegwit, PurposeTaproot}
+
+// keyVersion holds the serialization prefixes of a network and purpose (SLIP-132)
+type keyVersion struct {
+	private []byte
+	public  []byte
+	testnet bool
+	purpose uint32
+}
+
+var keyVersions = []keyVersion{
+	{[]byte{0x04, 0x88, 0xAD, 0xE4}, []byte{0x04, 0x88, 0xB2, 0x1E}, false, PurposeLegacy},       // xprv, xpub
+	{[]byte{0x04, 0x9D, 0x78, 0x78}, []byte{0x04, 0x9D, 0x7C, 0xB2}, false, PurposeNestedSegwit}, // yprv, ypub
+	{[]byte{0x04, 0xB2, 0x43, 0x0C}, []byte{0x04, 0xB2, 0x47, 0x46}, false, PurposeNativeSegwit}, // zprv, zpub
+	{[]byte{0x04, 0x35, 0x83, 0x94}, []byte{0x04, 0x35, 0x87, 0xCF}, true, PurposeLegacy},        // tprv, tpub
+	{[]byte{0x04, 0x4A, 0x4E, 0x28}, []byte{0x04, 0x4A, 0x52, 0x62}, true, PurposeNestedSegwit},  // uprv, upub
+	{[]byte{0x04, 0x5F, 0x18, 0xBC}, []byte{0x04, 0x5F, 0x1C, 0xF6}, true, PurposeNativeSegwit},  // vprv, vpub
+}
+
+// lookupVersion returns the prefixes for a network and purpose.
+// Purposes without a SLIP-132 prefix (like BIP86) use xprv/xpub or tprv/tpub.
+func lookupVersion(testnet bool, purpose uint32) keyVersion {
+	for _, version := range keyVersions {
+		if version.testnet == testnet && version.purpose == purpose {
+			return version
+		}
+	}
+	return lookupVersion(testnet, PurposeLegacy)
+}
+
+func findVersion(prefix []byte) (keyVersion, bool, bool) {
+	for _, version := range keyVersions {
+		if bytes.Equal(prefix, version.private) {
+			return version, true, true
+		}
+		if bytes.Equal(prefix, version.public) {
+			return version, false, true
+		}
+	}
+	return keyVersion{}, false, false
+}
+
+// StringSLIP132 returns the serialization of the key with the SLIP-132 prefix of the purpose,
+// like ypub for BIP49 or zpub for BIP84.
+func (k *ExtendedKey) StringSLIP132(purpose uint32) string {
+	return k.serialize(lookupVersion(k.params.Testnet, purpose))
+}
+
+// AccountPath returns the account derivation path m/purpose'/coin_type'/account'.
+func AccountPath(purpose uint32, params *chaincfg.Params, account uint32) string {
+	return fmt.Sprintf("m/%d'/%d'/%d'", purpose, params.HDCoinType, account)
+}
+
+// Account derives the account key m/purpose'/coin_type'/account' from a master key.
+func (k *ExtendedKey) Account(purpose, account uint32) (*ExtendedKey, error) {
+	if k.depth != 0 {
+		return nil, fmt.Errorf("account derivation needs a master key, got depth %d", k.depth)
+	}
+	return k.Derive(AccountPath(purpose, k.params, account))
+}
@@ -0,0 +1,28 @@
+package keys
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// ToAddressEthereum returns the EIP-55 checksummed Ethereum address of the key
+func (pub *publicKey) ToAddressEthereum() string {
+	hash := crypto.Keccak256(pub.pubkey)
+	return "0x" + eip55(hex.EncodeToString(hash[12:]))
+}
+
+// eip55 uppercases each hex letter whose nibble in Keccak256(lowercase address) is >= 8
+func eip55(address string) string {
+	hash := hex.EncodeToString(crypto.Keccak256([]byte(address)))
+
+	var checksummed strings.Builder
+	for i, c := range address {
+		if c >= 'a' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		checksummed.WriteRune(c)
+	}
+	return checksummed.String()
+}
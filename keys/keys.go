@@ -2,29 +2,60 @@ package keys
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/decred/dcrd/dcrec/secp256k1"
 	"github.com/ottosch/pick-private/base58"
 	"github.com/ottosch/pick-private/bech32"
+	"github.com/ottosch/pick-private/cashaddr"
+	"github.com/ottosch/pick-private/chaincfg"
 	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/script"
 )
 
 type PrivateKey struct {
 	privKey *big.Int
-	pubkey  []byte
-	testnet bool
+	publicKey
 }
 
-// FromBigInt creates a PrivateKey from a big.Int.
-func FromBigInt(number *big.Int, testnet bool) PrivateKey {
+// publicKey holds the uncompressed point (x || y) and derives every address and script from it
+type publicKey struct {
+	pubkey []byte
+	params *chaincfg.Params
+}
+
+// CheckPrivateKey returns an error unless number is a valid private key, from 1 to n-1
+func CheckPrivateKey(number *big.Int) error {
+	if number == nil {
+		return errors.New("invalid private key: empty")
+	}
+	if number.Sign() <= 0 || number.Cmp(secp256k1.S256().Params().N) >= 0 {
+		return fmt.Errorf("invalid private key: %s is out of range (1 to n-1)", number.Text(16))
+	}
+	return nil
+}
+
+// FromBigInt creates a PrivateKey from a big.Int for the given network. The number
+// must be in range (see CheckPrivateKey).
+func FromBigInt(number *big.Int, params *chaincfg.Params) PrivateKey {
 	secPrivKey, _ := secp256k1.PrivKeyFromBytes(number.Bytes())
 	x, y := secPrivKey.Public()
 	pubkey := make([]byte, 64)
 	x.FillBytes(pubkey[:32])
 	y.FillBytes(pubkey[32:])
-	return PrivateKey{number, pubkey, testnet}
+	return PrivateKey{number, publicKey{pubkey, params}}
+}
+
+// Public returns the watch-only public key of the private key
+func (priv *PrivateKey) Public() PublicKey {
+	return PublicKey{priv.publicKey}
+}
+
+// Params returns the network of the key
+func (pub *publicKey) Params() *chaincfg.Params {
+	return pub.params
 }
 
 // PrivateKey returns the internal *big.Int private key.
@@ -33,22 +64,22 @@ func (priv *PrivateKey) PrivateKey() *big.Int {
 }
 
 // PublicKey returns the compressed public key.
-func (priv *PrivateKey) PublicKey() []byte {
-	return priv.publicKey(true)
+func (pub *publicKey) PublicKey() []byte {
+	return pub.serialize(true)
 }
 
 // PublicKeyUncompressed returns the uncompressed public key.
-func (priv *PrivateKey) PublicKeyUncompressed() []byte {
-	return priv.publicKey(false)
+func (pub *publicKey) PublicKeyUncompressed() []byte {
+	return pub.serialize(false)
 }
 
-func (priv *PrivateKey) publicKey(compressed bool) []byte {
-	xBytes := priv.pubkey[:32]
-	yBytes := priv.pubkey[32:]
+func (pub *publicKey) serialize(compressed bool) []byte {
+	xBytes := pub.pubkey[:32]
+	yBytes := pub.pubkey[32:]
 
 	var pubkey []byte
 	if compressed {
-		if priv.pubkey[63]%2 == 0 {
+		if pub.pubkey[63]%2 == 0 {
 			pubkey = []byte{0x02}
 		} else {
 			pubkey = []byte{0x03}
@@ -75,128 +106,102 @@ func (priv *PrivateKey) ToWIFUncompressed() string {
 }
 
 func (priv *PrivateKey) toWif(compressed bool) string {
-	hexPriv := priv.privKey.Bytes()
-	hexPadded := fmt.Sprintf("%064x", hexPriv)
-
-	var extended string
-	switch {
-	case priv.testnet && compressed:
-		extended = fmt.Sprintf("EF%s01", hexPadded)
-	case priv.testnet && !compressed:
-		extended = fmt.Sprintf("EF%s", hexPadded)
-	case !priv.testnet && compressed:
-		extended = fmt.Sprintf("80%s01", hexPadded)
-	case !priv.testnet && !compressed:
-		extended = fmt.Sprintf("80%s", hexPadded)
+	payload := make([]byte, 32)
+	priv.privKey.FillBytes(payload)
+	if compressed {
+		payload = append(payload, 0x01)
 	}
 
-	hexExtended, _ := hex.DecodeString(extended)
-	checksum := crypto.Hash256(hexExtended)[:4]
-	checksumString := hex.EncodeToString(checksum[:])
-	return base58.Encode(extended + checksumString)
+	return base58.CheckEncode([]byte{priv.params.PrivateKeyID}, payload)
 }
 
 // ToLegacy returns the legacy address (compressed public key)
-func (priv *PrivateKey) ToAddressLegacy() string {
-	return priv.p2pkh(true)
+func (pub *publicKey) ToAddressLegacy() string {
+	return pub.p2pkh(true)
 }
 
 // ToLegacyUncompressed returns the legacy address (uncompressed public key)
-func (priv *PrivateKey) ToAddressLegacyUncompressed() string {
-	return priv.p2pkh(false)
+func (pub *publicKey) ToAddressLegacyUncompressed() string {
+	return pub.p2pkh(false)
 }
 
 // ToScriptLegacy returns the P2PKH scriptPubKey (compressed public key)
-func (priv *PrivateKey) ToScriptLegacy() string {
-	return priv.legacyScript(true)
+func (pub *publicKey) ToScriptLegacy() string {
+	return pub.legacyScript(true)
 }
 
 // ToScriptLegacyUncompressed returns the P2PKH scriptPubKey (uncompressed public key)
-func (priv *PrivateKey) ToScriptLegacyUncompressed() string {
-	return priv.legacyScript(false)
+func (pub *publicKey) ToScriptLegacyUncompressed() string {
+	return pub.legacyScript(false)
 }
 
-func (priv *PrivateKey) legacyScript(compressed bool) string {
-	pkh := hex.EncodeToString(priv.pkh(compressed))
-	return fmt.Sprintf("76a914%s88ac", pkh)
+func (pub *publicKey) legacyScript(compressed bool) string {
+	return hex.EncodeToString(script.P2PKH(pub.pkh(compressed)))
 }
 
 // ToScriptSegwitCompat returns the P2SH-P2WPKH scriptPubKey
-func (priv *PrivateKey) ToScriptSegwitCompat() string {
-	redeem := []byte{0x00, 0x14}
-	redeem = append(redeem, crypto.Hash160(priv.PublicKey())...)
-	hash160Redeem := hex.EncodeToString(crypto.Hash160(redeem))
-	return fmt.Sprintf("a914%s87", hash160Redeem)
+func (pub *publicKey) ToScriptSegwitCompat() string {
+	redeem := script.WitnessProgram(0, pub.pkh(true))
+	return hex.EncodeToString(script.P2SH(crypto.Hash160(redeem)))
 }
 
 // ToScriptSegwit returns the P2WPKH scriptPubKey
-func (priv *PrivateKey) ToScriptSegwit() string {
-	pkh := hex.EncodeToString(priv.pkh(true))
-	return fmt.Sprintf("0014%s", pkh)
+func (pub *publicKey) ToScriptSegwit() string {
+	return hex.EncodeToString(script.WitnessProgram(0, pub.pkh(true)))
 }
 
 // ToPublicKeyHash returns the (compressed) public key hash
-func (priv *PrivateKey) ToPublicKeyHash() []byte {
-	return priv.pkh(true)
+func (pub *publicKey) ToPublicKeyHash() []byte {
+	return pub.pkh(true)
 }
 
 // ToPublicKeyHashUncompressed returns the (uncompressed) public key hash
-func (priv *PrivateKey) ToPublicKeyHashUncompressed() []byte {
-	return priv.pkh(false)
+func (pub *publicKey) ToPublicKeyHashUncompressed() []byte {
+	return pub.pkh(false)
 }
 
-func (priv *PrivateKey) pkh(compressed bool) []byte {
-	return crypto.Hash160(priv.publicKey(compressed))
+func (pub *publicKey) pkh(compressed bool) []byte {
+	return crypto.Hash160(pub.serialize(compressed))
 }
 
-func (priv *PrivateKey) p2pkh(compressed bool) string {
-	hash160 := priv.pkh(compressed)
-	data := []byte{0x00}
-	if priv.testnet {
-		data[0] = 0x6F
-	}
-
-	extendedHash160 := append(data, hash160...)
-	checksum := crypto.Hash256(extendedHash160)[0:4]
-	final := append(extendedHash160, checksum...)
-
-	return base58.Encode(hex.EncodeToString(final))
+func (pub *publicKey) p2pkh(compressed bool) string {
+	hash160 := pub.pkh(compressed)
+	return base58.CheckEncode([]byte{pub.params.PubKeyHashAddrID}, hash160)
 }
 
 // ToSegWitCompat the P2SH-SegWit address
-func (priv *PrivateKey) ToAddressSegWitCompat() string {
-	pubkey := priv.PublicKey()
-
-	redeem := []byte{0x00, 0x14}
-	redeem = append(redeem, crypto.Hash160(pubkey)...)
-
+func (pub *publicKey) ToAddressSegWitCompat() string {
+	redeem := script.WitnessProgram(0, pub.pkh(true))
 	hash160Redeem := crypto.Hash160(redeem)
-	data := []byte{0x05}
-	if priv.testnet {
-		data[0] = 0xC4
-	}
-
-	extendedHash160 := append(data, hash160Redeem...)
-	checksum := crypto.Hash256(extendedHash160)[0:4]
-	final := append(extendedHash160, checksum...)
-
-	return base58.Encode(hex.EncodeToString(final))
+	return base58.CheckEncode([]byte{pub.params.ScriptHashAddrID}, hash160Redeem)
 }
 
 // ToSegWit the P2SH-SegWit address
-func (priv *PrivateKey) ToAddressSegWit() string {
-	hash160PubKey := crypto.Hash160(priv.PublicKey())
+func (pub *publicKey) ToAddressSegWit() string {
+	hash160PubKey := crypto.Hash160(pub.PublicKey())
 
 	program := make([]int, len(hash160PubKey))
 	for i, b := range hash160PubKey {
 		program[i] = int(b)
 	}
 
-	hrp := "bc"
-	if priv.testnet {
-		hrp = "tb"
-	}
-
-	addr, _ := bech32.SegwitAddrEncode(hrp, 0, program)
+	addr, _ := bech32.SegwitAddrEncode(pub.params.Bech32HRP, 0, program)
 	return addr
 }
+
+// ToAddressCashAddr returns the Bitcoin Cash CashAddr P2PKH address (compressed public key)
+func (pub *publicKey) ToAddressCashAddr() (string, error) {
+	return pub.p2pkhCashAddr(true)
+}
+
+// ToAddressCashAddrUncompressed returns the Bitcoin Cash CashAddr P2PKH address (uncompressed public key)
+func (pub *publicKey) ToAddressCashAddrUncompressed() (string, error) {
+	return pub.p2pkhCashAddr(false)
+}
+
+func (pub *publicKey) p2pkhCashAddr(compressed bool) (string, error) {
+	if pub.params.CashAddrPrefix == "" {
+		return "", fmt.Errorf("%s has no CashAddr format", pub.params.Coin)
+	}
+	return cashaddr.Encode(pub.params.CashAddrPrefix, cashaddr.TypeP2PKH, pub.pkh(compressed))
+}
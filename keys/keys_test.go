@@ -5,17 +5,19 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/ottosch/pick-private/chaincfg"
 	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/taproot"
 )
 
 type network struct {
-	name    string
-	testnet bool
+	name   string
+	params *chaincfg.Params
 }
 
 var networks = []network{
-	{"mainnet", false},
-	{"testnet", true},
+	{"mainnet", chaincfg.MainNet},
+	{"testnet", chaincfg.TestNet3},
 }
 
 type testData struct {
@@ -34,6 +36,8 @@ type testData struct {
 	scriptSegwitCompat        string
 	addressSegWit             string
 	scriptSegwit              string
+	addressTaproot            string
+	scriptTaproot             string
 }
 
 var testsMainnet = []testData{
@@ -53,6 +57,8 @@ var testsMainnet = []testData{
 		scriptSegwitCompat:        "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487",
 		addressSegWit:             "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
 		scriptSegwit:              "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		addressTaproot:            "bc1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5sspknck9",
+		scriptTaproot:             "5120da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21",
 	},
 	{
 		input:                     big.NewInt(12345),
@@ -70,6 +76,8 @@ var testsMainnet = []testData{
 		scriptSegwitCompat:        "a91468bd923bc087818d8f16418a4418741bef69b47b87",
 		addressSegWit:             "bc1qz5s0ppmjpcvprqpdakdu8qqcm2v3z8us334at6",
 		scriptSegwit:              "00141520f087720e1811802ded9bc38018da99111f90",
+		addressTaproot:            "bc1ptww0hyfzv6zy5cn9sg8jdqzjkmzsp222ujvv3dg2ej83cs7mnkhsrwt5z9",
+		scriptTaproot:             "51205b9cfb912266844a6265820f268052b6c500a94ae498c8b50acc8f1c43db9daf",
 	},
 	{
 		input:                     hexToBigInt("9ae65d9154ac2490d7fb3f5e63d37d174a2e8d8a1744f9114f6486f315c08f06"),
@@ -87,6 +95,8 @@ var testsMainnet = []testData{
 		scriptSegwitCompat:        "a914f31eb5282c316e7dab6fd782021e555f577a577b87",
 		addressSegWit:             "bc1q26an2ceceg0p8d9taftrwfl7cuu556fd6rr4jy",
 		scriptSegwit:              "001456bb356338ca1e13b4abea563727fec7394a692d",
+		addressTaproot:            "bc1p4sg9y2rz8mer074gkrhhly0qty9jyyuftp7l8lxugd9tmfs54sdqp77lqe",
+		scriptTaproot:             "5120ac105228623ef237faa8b0ef7f91e0590b221389587df3fcdc434abda614ac1a",
 	},
 }
 
@@ -107,6 +117,8 @@ var testsTestnet = []testData{
 		scriptSegwitCompat:        "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487",
 		addressSegWit:             "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx",
 		scriptSegwit:              "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		addressTaproot:            "tb1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5ssk79hv2",
+		scriptTaproot:             "5120da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21",
 	},
 	{
 		input:                     big.NewInt(12345),
@@ -124,6 +136,8 @@ var testsTestnet = []testData{
 		scriptSegwitCompat:        "a91468bd923bc087818d8f16418a4418741bef69b47b87",
 		addressSegWit:             "tb1qz5s0ppmjpcvprqpdakdu8qqcm2v3z8usmhwwsf",
 		scriptSegwit:              "00141520f087720e1811802ded9bc38018da99111f90",
+		addressTaproot:            "tb1ptww0hyfzv6zy5cn9sg8jdqzjkmzsp222ujvv3dg2ej83cs7mnkhs5xamc2",
+		scriptTaproot:             "51205b9cfb912266844a6265820f268052b6c500a94ae498c8b50acc8f1c43db9daf",
 	},
 	{
 		input:                     hexToBigInt("9ae65d9154ac2490d7fb3f5e63d37d174a2e8d8a1744f9114f6486f315c08f06"),
@@ -141,6 +155,8 @@ var testsTestnet = []testData{
 		scriptSegwitCompat:        "a914f31eb5282c316e7dab6fd782021e555f577a577b87",
 		addressSegWit:             "tb1q26an2ceceg0p8d9taftrwfl7cuu556fds9cxfh",
 		scriptSegwit:              "001456bb356338ca1e13b4abea563727fec7394a692d",
+		addressTaproot:            "tb1p4sg9y2rz8mer074gkrhhly0qty9jyyuftp7l8lxugd9tmfs54sdqkkgs6k",
+		scriptTaproot:             "5120ac105228623ef237faa8b0ef7f91e0590b221389587df3fcdc434abda614ac1a",
 	},
 }
 
@@ -152,13 +168,13 @@ func hexToBigInt(hex string) *big.Int {
 func TestPublicKey(t *testing.T) {
 	for _, network := range networks {
 		var testCases []testData
-		if network.testnet {
+		if network.params.Testnet {
 			testCases = testsTestnet
 		} else {
 			testCases = testsMainnet
 		}
 		for _, test := range testCases {
-			privateKey := keys.FromBigInt(test.input, network.testnet)
+			privateKey := keys.FromBigInt(test.input, network.params)
 			pubkey := hex.EncodeToString(privateKey.PublicKey())
 			pubkeyUncompressed := hex.EncodeToString(privateKey.PublicKeyUncompressed())
 
@@ -180,13 +196,13 @@ func TestPublicKey(t *testing.T) {
 func TestPublicKeyHash(t *testing.T) {
 	for _, network := range networks {
 		var testCases []testData
-		if network.testnet {
+		if network.params.Testnet {
 			testCases = testsTestnet
 		} else {
 			testCases = testsMainnet
 		}
 		for _, test := range testCases {
-			privateKey := keys.FromBigInt(test.input, network.testnet)
+			privateKey := keys.FromBigInt(test.input, network.params)
 			pubkeyHash := hex.EncodeToString(privateKey.ToPublicKeyHash())
 			pubkeyHashUncompressed := hex.EncodeToString(privateKey.ToPublicKeyHashUncompressed())
 
@@ -208,13 +224,13 @@ func TestPublicKeyHash(t *testing.T) {
 func TestWIF(t *testing.T) {
 	for _, network := range networks {
 		var testCases []testData
-		if network.testnet {
+		if network.params.Testnet {
 			testCases = testsTestnet
 		} else {
 			testCases = testsMainnet
 		}
 		for _, test := range testCases {
-			privateKey := keys.FromBigInt(test.input, network.testnet)
+			privateKey := keys.FromBigInt(test.input, network.params)
 			wif := privateKey.ToWIF()
 			wifUncompressed := privateKey.ToWIFUncompressed()
 
@@ -236,17 +252,18 @@ func TestWIF(t *testing.T) {
 func TestAddress(t *testing.T) {
 	for _, network := range networks {
 		var testCases []testData
-		if network.testnet {
+		if network.params.Testnet {
 			testCases = testsTestnet
 		} else {
 			testCases = testsMainnet
 		}
 		for _, test := range testCases {
-			privateKey := keys.FromBigInt(test.input, network.testnet)
+			privateKey := keys.FromBigInt(test.input, network.params)
 			addressLegacy := privateKey.ToAddressLegacy()
 			addressLegacyUncompressed := privateKey.ToAddressLegacyUncompressed()
 			addressSegWitCompat := privateKey.ToAddressSegWitCompat()
 			addressSegWit := privateKey.ToAddressSegWit()
+			addressTaproot := privateKey.ToAddressTaproot()
 
 			if addressLegacy != test.addressLegacy {
 				t.Errorf("ToAddressLegacy for [%s] %d FAILED. Expected %s, got %s\n", network.name, test.input, test.addressLegacy, addressLegacy)
@@ -271,6 +288,12 @@ func TestAddress(t *testing.T) {
 			} else {
 				t.Logf("ToAddressSegWit passed: [%s] %d, %s\n", network.name, test.input, test.addressSegWit)
 			}
+
+			if addressTaproot != test.addressTaproot {
+				t.Errorf("ToAddressTaproot for [%s] %d FAILED. Expected %s, got %s\n", network.name, test.input, test.addressTaproot, addressTaproot)
+			} else {
+				t.Logf("ToAddressTaproot passed: [%s] %d, %s\n", network.name, test.input, test.addressTaproot)
+			}
 		}
 	}
 }
@@ -278,17 +301,18 @@ func TestAddress(t *testing.T) {
 func TestScript(t *testing.T) {
 	for _, network := range networks {
 		var testCases []testData
-		if network.testnet {
+		if network.params.Testnet {
 			testCases = testsTestnet
 		} else {
 			testCases = testsMainnet
 		}
 		for _, test := range testCases {
-			privateKey := keys.FromBigInt(test.input, network.testnet)
+			privateKey := keys.FromBigInt(test.input, network.params)
 			scriptLegacy := privateKey.ToScriptLegacy()
 			scriptLegacyUncompressed := privateKey.ToScriptLegacyUncompressed()
 			scriptSegwitCompat := privateKey.ToScriptSegwitCompat()
 			scriptSegwit := privateKey.ToScriptSegwit()
+			scriptTaproot := privateKey.ToScriptTaproot()
 
 			if scriptLegacy != test.scriptLegacy {
 				t.Errorf("ToScriptLegacy for [%s] %d FAILED. Expected %s, got %s\n", network.name, test.input, test.scriptLegacy, scriptLegacy)
@@ -313,6 +337,301 @@ func TestScript(t *testing.T) {
 			} else {
 				t.Logf("ToScriptSegwit passed: [%s] %d, %s\n", network.name, test.input, test.scriptSegwit)
 			}
+
+			if scriptTaproot != test.scriptTaproot {
+				t.Errorf("ToScriptTaproot for [%s] %d FAILED. Expected %s, got %s\n", network.name, test.input, test.scriptTaproot, scriptTaproot)
+			} else {
+				t.Logf("ToScriptTaproot passed: [%s] %d, %s\n", network.name, test.input, test.scriptTaproot)
+			}
+		}
+	}
+}
+
+func TestPublicKeyFromHex(t *testing.T) {
+	for _, network := range networks {
+		var testCases []testData
+		if network.params.Testnet {
+			testCases = testsTestnet
+		} else {
+			testCases = testsMainnet
+		}
+		for _, test := range testCases {
+			for _, input := range []string{test.pubkey, test.pubkeyUncompressed} {
+				publicKey, err := keys.PublicKeyFromHex(input, network.params)
+				if err != nil {
+					t.Errorf("PublicKeyFromHex for [%s] %s FAILED: %v\n", network.name, input, err)
+					continue
+				}
+
+				got := []string{
+					hex.EncodeToString(publicKey.PublicKey()),
+					hex.EncodeToString(publicKey.PublicKeyUncompressed()),
+					publicKey.ToAddressLegacy(),
+					publicKey.ToAddressLegacyUncompressed(),
+					publicKey.ToScriptSegwitCompat(),
+					publicKey.ToAddressSegWit(),
+					publicKey.ToAddressTaproot(),
+				}
+				expected := []string{test.pubkey, test.pubkeyUncompressed, test.addressLegacy, test.addressLegacyUncompressed,
+					test.scriptSegwitCompat, test.addressSegWit, test.addressTaproot}
+
+				for i := range expected {
+					if got[i] != expected[i] {
+						t.Errorf("PublicKeyFromHex for [%s] %s FAILED. Expected %s, got %s\n", network.name, input, expected[i], got[i])
+					}
+				}
+				t.Logf("PublicKeyFromHex passed: [%s] %s\n", network.name, input)
+			}
+		}
+	}
+}
+
+func TestPublicKeyFromHexInvalid(t *testing.T) {
+	invalid := []string{
+		"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"0579be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b9",
+		"0679be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		"02fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30",
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f8179g",
+	}
+
+	for _, input := range invalid {
+		if publicKey, err := keys.PublicKeyFromHex(input, chaincfg.MainNet); err == nil {
+			t.Errorf("PublicKeyFromHex of %s passed, should've failed: %x\n", input, publicKey.PublicKey())
+		} else {
+			t.Logf("PublicKeyFromHex passed: %s rejected, %v\n", input, err)
+		}
+	}
+}
+
+type taprootTreeTestData struct {
+	input        *big.Int
+	leaves       []string
+	address      string
+	script       string
+	controlBlock string
+}
+
+var taprootTreeTests = []taprootTreeTestData{
+	{
+		input:        big.NewInt(1),
+		leaves:       []string{"51"},
+		address:      "bc1pndkwpkc8ql3fly4l3zf76xg36wt785khdw7xsygvf8dzemkghc3sqkwrav",
+		script:       "51209b6ce0db0707e29f92bf8893ed1911d397e3d2d76bbc68110c49da2ceec8be23",
+		controlBlock: "c079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	},
+}
+
+func TestTaprootTree(t *testing.T) {
+	for _, test := range taprootTreeTests {
+		var leaves []taproot.Leaf
+		for _, s := range test.leaves {
+			leaf, _ := taproot.ParseLeaf(s)
+			leaves = append(leaves, leaf)
+		}
+		tree, _ := taproot.NewTree(leaves)
+
+		privateKey := keys.FromBigInt(test.input, chaincfg.MainNet)
+		address := privateKey.ToAddressTaprootTree(tree)
+		script := privateKey.ToScriptTaprootTree(tree)
+		controlBlock := hex.EncodeToString(privateKey.TaprootControlBlock(tree, 0))
+
+		if address != test.address {
+			t.Errorf("ToAddressTaprootTree for %d FAILED. Expected %s, got %s\n", test.input, test.address, address)
+		} else {
+			t.Logf("ToAddressTaprootTree passed: %d, %s\n", test.input, test.address)
+		}
+
+		if script != test.script {
+			t.Errorf("ToScriptTaprootTree for %d FAILED. Expected %s, got %s\n", test.input, test.script, script)
+		} else {
+			t.Logf("ToScriptTaprootTree passed: %d, %s\n", test.input, test.script)
+		}
+
+		if controlBlock != test.controlBlock {
+			t.Errorf("TaprootControlBlock for %d FAILED. Expected %s, got %s\n", test.input, test.controlBlock, controlBlock)
+		} else {
+			t.Logf("TaprootControlBlock passed: %d, %s\n", test.input, test.controlBlock)
+		}
+	}
+}
+
+func TestRegtest(t *testing.T) {
+	privateKey := keys.FromBigInt(big.NewInt(1), chaincfg.RegTest)
+	results := []struct{ name, expected, result string }{
+		{"ToWIF", "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", privateKey.ToWIF()},
+		{"ToAddressLegacy", "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", privateKey.ToAddressLegacy()},
+		{"ToAddressSegWit", "bcrt1qw508d6qejxtdg4y5r3zarvary0c5xw7kygt080", privateKey.ToAddressSegWit()},
+		{"ToAddressTaproot", "bcrt1pmfr3p9j00pfxjh0zmgp99y8zftmd3s5pmedqhyptwy6lm87hf5ssm803es", privateKey.ToAddressTaproot()},
+	}
+
+	for _, r := range results {
+		if r.result != r.expected {
+			t.Errorf("%s for [regtest] 1 FAILED. Expected %s, got %s\n", r.name, r.expected, r.result)
+		} else {
+			t.Logf("%s passed: [regtest] 1, %s\n", r.name, r.expected)
+		}
+	}
+}
+
+type altcoinTestData struct {
+	params        *chaincfg.Params
+	wif           string
+	addressLegacy string
+	addressCompat string
+	addressSegWit string
+}
+
+var altcoinTests = []altcoinTestData{
+	{chaincfg.LitecoinMainNet, "T33ydQRKp4FCW5LCLLUB7deioUMoveiwekdwUwyfRDeGZm76aUjV", "LVuDpNCSSj6pQ7t9Pv6d6sUkLKoqDEVUnJ", "MR8UQSBr5ULwWheBHznrHk2jxyxkHQu8vB", "ltc1qw508d6qejxtdg4y5r3zarvary0c5xw7kgmn4n9"},
+	{chaincfg.LitecoinTestNet, "cMahea7zqjxrtgAbB7LSGbcQUr1uX1ojuat9jZodMN87JcbXMTcA", "mrCDrCybB6J1vRfbwM5hemdJz73FwDBC8r", "QdqJHJa9kv3x4AksVMTQAkD3122J1Pbb8p", "tltc1qw508d6qejxtdg4y5r3zarvary0c5xw7klfsuq0"},
+	{chaincfg.DogecoinMainNet, "QNcdLVw8fHkixm6NNyN6nVwxKek4u7qrioRbQmjxac5TVoTtZuot", "DFpN6QqFfUm3gKNaxN6tNcab1FArL9cZLE", "", ""},
+}
+
+func TestAltcoin(t *testing.T) {
+	for _, test := range altcoinTests {
+		privateKey := keys.FromBigInt(big.NewInt(1), test.params)
+		results := []struct{ name, expected, result string }{
+			{"ToWIF", test.wif, privateKey.ToWIF()},
+			{"ToAddressLegacy", test.addressLegacy, privateKey.ToAddressLegacy()},
+		}
+		if test.params.SegWit {
+			results = append(results,
+				struct{ name, expected, result string }{"ToAddressSegWitCompat", test.addressCompat, privateKey.ToAddressSegWitCompat()},
+				struct{ name, expected, result string }{"ToAddressSegWit", test.addressSegWit, privateKey.ToAddressSegWit()},
+			)
+		}
+
+		for _, r := range results {
+			if r.result != r.expected {
+				t.Errorf("%s for [%s %s] 1 FAILED. Expected %s, got %s\n", r.name, test.params.Coin, test.params.Name, r.expected, r.result)
+			} else {
+				t.Logf("%s passed: [%s %s] 1, %s\n", r.name, test.params.Coin, test.params.Name, r.expected)
+			}
+		}
+	}
+}
+
+func TestCashAddr(t *testing.T) {
+	privateKey := keys.FromBigInt(big.NewInt(1), chaincfg.BitcoinCashMainNet)
+	expected := "bitcoincash:qp63uahgrxged4z5jswyt5dn5v3lzsem6cy4spdc2h"
+	if result, err := privateKey.ToAddressCashAddr(); err != nil || result != expected {
+		t.Errorf("ToAddressCashAddr for 1 FAILED. Expected %s, got %s (%v)\n", expected, result, err)
+	} else {
+		t.Logf("ToAddressCashAddr passed: 1, %s\n", expected)
+	}
+
+	bitcoinKey := keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+	if _, err := bitcoinKey.ToAddressCashAddr(); err == nil {
+		t.Errorf("ToAddressCashAddr for a btc key passed, should've failed: FAIL\n")
+	}
+}
+
+type ethereumTestData struct {
+	input   *big.Int
+	address string
+}
+
+var ethereumTests = []ethereumTestData{
+	{big.NewInt(1), "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"},
+	{big.NewInt(2), "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF"},
+	{big.NewInt(3), "0x6813Eb9362372EEF6200f3b1dbC3f819671cBA69"},
+}
+
+func TestEthereum(t *testing.T) {
+	for _, test := range ethereumTests {
+		privateKey := keys.FromBigInt(test.input, chaincfg.MainNet)
+		address := privateKey.ToAddressEthereum()
+		if address != test.address {
+			t.Errorf("ToAddressEthereum for %d FAILED. Expected %s, got %s\n", test.input, test.address, address)
+		} else {
+			t.Logf("ToAddressEthereum passed: %d, %s\n", test.input, test.address)
+		}
+	}
+}
+
+type signTestData struct {
+	input   *big.Int
+	digest  string
+	ecdsa   string
+	schnorr string
+}
+
+// RFC6979 vectors: sha256 of "Satoshi Nakamoto" and of "All those moments will be lost in time,
+// like tears in rain. Time to die..."
+var signTests = []signTestData{
+	{big.NewInt(1), "a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e", "3045022100934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d802202442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5", "1c22a20154670759be55cfa1a4b1c4d8e203b2e3ce433c4c0f9326913b545c9cfeb67e44bacc4dd00a48e986b7ce0717e91ca24f1853c430dc8bebce367862f0"},
+	{big.NewInt(1), "7d1833f54854ac51659521afcd0ec6dca2ce2351429614bfa28a756b1b3c637f", "30450221008600dbd41e348fe5c9465ab92d23e3db8b98b873beecd930736488696438cb6b0220547fe64427496db33bf66019dacbf0039c04199abb0122918601db38a72cfc21", "60ce2aae065366a5292fa162654b0aef0f0a2531a17837157eb5701528da987598f518cb420433122c7a9a44d0d1e20257b874f5f507e8eae55aadc03c905d30"},
+}
+
+func TestSign(t *testing.T) {
+	for _, test := range signTests {
+		privateKey := keys.FromBigInt(test.input, chaincfg.MainNet)
+		digest, _ := hex.DecodeString(test.digest)
+
+		ecdsa, err := privateKey.SignECDSA(digest)
+		switch {
+		case err != nil:
+			t.Errorf("SignECDSA for %d FAILED: %v\n", test.input, err)
+		case hex.EncodeToString(ecdsa) != test.ecdsa:
+			t.Errorf("SignECDSA for %d FAILED. Expected %s, got %x\n", test.input, test.ecdsa, ecdsa)
+		case keys.VerifyECDSA(privateKey.PublicKey(), digest, ecdsa) != nil:
+			t.Errorf("VerifyECDSA for %d FAILED\n", test.input)
+		default:
+			t.Logf("SignECDSA passed: %d, %s\n", test.input, test.ecdsa)
+		}
+
+		schnorr, err := privateKey.SignSchnorr(digest, nil)
+		switch {
+		case err != nil:
+			t.Errorf("SignSchnorr for %d FAILED: %v\n", test.input, err)
+		case hex.EncodeToString(schnorr) != test.schnorr:
+			t.Errorf("SignSchnorr for %d FAILED. Expected %s, got %x\n", test.input, test.schnorr, schnorr)
+		case keys.VerifySchnorr(privateKey.TaprootInternalKey(), digest, schnorr) != nil:
+			t.Errorf("VerifySchnorr for %d FAILED\n", test.input)
+		default:
+			t.Logf("SignSchnorr passed: %d, %s\n", test.input, test.schnorr)
+		}
+
+		digest[0] ^= 0x01
+		if keys.VerifyECDSA(privateKey.PublicKey(), digest, ecdsa) == nil || keys.VerifySchnorr(privateKey.TaprootInternalKey(), digest, schnorr) == nil {
+			t.Errorf("Verify of another digest for %d passed, should've failed: FAIL\n", test.input)
+		}
+	}
+}
+
+func TestCheckPrivateKey(t *testing.T) {
+	tests := map[string]bool{
+		"0": false,
+		"1": true,
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140":   true,
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141":   false,
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff": false,
+	}
+
+	for input, valid := range tests {
+		number, _ := new(big.Int).SetString(input, 16)
+		if err := keys.CheckPrivateKey(number); (err == nil) != valid {
+			t.Errorf("CheckPrivateKey for %s FAILED. Expected valid %t, got %v\n", input, valid, err)
+		} else {
+			t.Logf("CheckPrivateKey passed: %s\n", input)
+		}
+	}
+}
+
+func TestSignInvalidKey(t *testing.T) {
+	digest := make([]byte, 32)
+	for _, number := range []*big.Int{big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), 264)} {
+		privateKey := keys.FromBigInt(number, chaincfg.MainNet)
+		if _, err := privateKey.SignECDSA(digest); err == nil {
+			t.Errorf("SignECDSA with key %x passed, should've failed: FAIL\n", number)
+		}
+		if _, err := privateKey.SignSchnorr(digest, nil); err == nil {
+			t.Errorf("SignSchnorr with key %x passed, should've failed: FAIL\n", number)
+		}
+		if _, err := privateKey.SignTaproot(digest, nil); err == nil {
+			t.Errorf("SignTaproot with key %x passed, should've failed: FAIL\n", number)
 		}
 	}
 }
@@ -0,0 +1,38 @@
+package keys
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/chaincfg"
+)
+
+// PublicKey is a watch-only key, with the same address and script methods as PrivateKey
+type PublicKey struct {
+	publicKey
+}
+
+// PublicKeyFromHex parses a 33-byte compressed or 65-byte uncompressed hex public key
+func PublicKeyFromHex(s string, params *chaincfg.Params) (PublicKey, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key: %v", err)
+	}
+	return PublicKeyFromBytes(data, params)
+}
+
+// PublicKeyFromBytes parses a compressed or uncompressed public key, checking that it is on the curve
+func PublicKeyFromBytes(data []byte, params *chaincfg.Params) (PublicKey, error) {
+	if len(data) != 33 && len(data) != 65 {
+		return PublicKey{}, fmt.Errorf("invalid public key length: %d", len(data))
+	}
+
+	secPubKey, err := secp256k1.ParsePubKey(data)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("invalid public key: %v", err)
+	}
+
+	pubkey := secPubKey.SerializeUncompressed()[1:]
+	return PublicKey{publicKey{pubkey, params}}, nil
+}
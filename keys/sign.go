@@ -0,0 +1,99 @@
+package keys
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/schnorr"
+	"github.com/ottosch/pick-private/taproot"
+)
+
+// SignECDSA returns the DER ECDSA signature of a 32-byte digest (low-S, RFC6979 nonce)
+func (priv *PrivateKey) SignECDSA(digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+
+	key, err := priv.bytes()
+	if err != nil {
+		return nil, err
+	}
+	secPrivKey, _ := secp256k1.PrivKeyFromBytes(key)
+	signature, err := secPrivKey.Sign(digest)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Serialize(), nil
+}
+
+// SignSchnorr returns the BIP340 signature of a 32-byte digest for the x-only public key
+// (TaprootInternalKey). auxRand is the 32-byte auxiliary randomness, nil for zeros.
+func (priv *PrivateKey) SignSchnorr(digest, auxRand []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	key, err := priv.bytes()
+	if err != nil {
+		return nil, err
+	}
+	return schnorr.Sign(key, digest, auxRand)
+}
+
+// SignTaproot returns the BIP340 signature of a 32-byte digest for a P2TR key-path spend,
+// signing with the key tweaked as in TaprootOutputKey. auxRand is as in SignSchnorr.
+func (priv *PrivateKey) SignTaproot(digest, auxRand []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+
+	key, err := priv.bytes()
+	if err != nil {
+		return nil, err
+	}
+	tweaked, err := taproot.TweakPrivateKey(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return schnorr.Sign(tweaked, digest, auxRand)
+}
+
+// VerifyECDSA checks a DER ECDSA signature of a 32-byte digest against a public key
+func VerifyECDSA(pubkey, digest, signature []byte) error {
+	if len(digest) != 32 {
+		return fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+
+	key, err := secp256k1.ParsePubKey(pubkey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+	sig, err := secp256k1.ParseDERSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	if !sig.Verify(digest, key) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// VerifySchnorr checks a BIP340 signature of a 32-byte digest against an x-only public key
+func VerifySchnorr(pubkey, digest, signature []byte) error {
+	if len(digest) != 32 {
+		return fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+	return schnorr.Verify(pubkey, digest, signature)
+}
+
+// bytes returns the 32-byte private key, refusing keys outside 1..n-1 before signing
+func (priv *PrivateKey) bytes() ([]byte, error) {
+	if priv.privKey == nil {
+		return nil, errors.New("invalid private key: empty")
+	}
+	if err := CheckPrivateKey(priv.privKey); err != nil {
+		return nil, err
+	}
+	return priv.privKey.FillBytes(make([]byte, 32)), nil
+}
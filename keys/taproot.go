@@ -0,0 +1,78 @@
+package keys
+
+import (
+	"encoding/hex"
+
+	"github.com/ottosch/pick-private/bech32"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/taproot"
+)
+
+// TaprootInternalKey returns the x-only internal public key (BIP340)
+func (pub *publicKey) TaprootInternalKey() []byte {
+	return pub.pubkey[:32]
+}
+
+// TaprootOutputKey returns the x-only output key, tweaked without a script tree (BIP341/BIP86)
+func (pub *publicKey) TaprootOutputKey() []byte {
+	outputKey, _ := pub.taprootOutputKey(nil)
+	return outputKey
+}
+
+// ToAddressTaproot returns the P2TR key-path address
+func (pub *publicKey) ToAddressTaproot() string {
+	return pub.p2tr(nil)
+}
+
+// ToScriptTaproot returns the P2TR scriptPubKey
+func (pub *publicKey) ToScriptTaproot() string {
+	return pub.taprootScript(nil)
+}
+
+// TaprootOutputKeyTree returns the x-only output key, tweaked with the script tree's merkle root
+func (pub *publicKey) TaprootOutputKeyTree(tree *taproot.Tree) []byte {
+	outputKey, _ := pub.taprootOutputKey(tree)
+	return outputKey
+}
+
+// ToAddressTaprootTree returns the P2TR address committing to a script tree
+func (pub *publicKey) ToAddressTaprootTree(tree *taproot.Tree) string {
+	return pub.p2tr(tree)
+}
+
+// ToScriptTaprootTree returns the P2TR scriptPubKey committing to a script tree
+func (pub *publicKey) ToScriptTaprootTree(tree *taproot.Tree) string {
+	return pub.taprootScript(tree)
+}
+
+// TaprootControlBlock returns the control block for a script-path spend of the i-th leaf
+func (pub *publicKey) TaprootControlBlock(tree *taproot.Tree, i int) []byte {
+	_, parity := pub.taprootOutputKey(tree)
+	return taproot.ControlBlock(tree, i, pub.TaprootInternalKey(), parity)
+}
+
+func (pub *publicKey) taprootOutputKey(tree *taproot.Tree) ([]byte, byte) {
+	var merkleRoot []byte
+	if tree != nil {
+		merkleRoot = tree.Root()
+	}
+
+	outputKey, parity, _ := taproot.TweakPublicKey(pub.TaprootInternalKey(), merkleRoot)
+	return outputKey, parity
+}
+
+func (pub *publicKey) taprootScript(tree *taproot.Tree) string {
+	return hex.EncodeToString(script.WitnessProgram(1, pub.TaprootOutputKeyTree(tree)))
+}
+
+func (pub *publicKey) p2tr(tree *taproot.Tree) string {
+	outputKey := pub.TaprootOutputKeyTree(tree)
+
+	program := make([]int, len(outputKey))
+	for i, b := range outputKey {
+		program[i] = int(b)
+	}
+
+	addr, _ := bech32.SegwitAddrEncode(pub.params.Bech32HRP, 1, program)
+	return addr
+}
@@ -0,0 +1,129 @@
+package message
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bech32"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+)
+
+const magic = "\x18Bitcoin Signed Message:\n"
+
+// AddressType is the address type a BIP137 signature commits to in its header byte
+type AddressType byte
+
+// BIP137 header byte offsets, added to 27 + recovery id
+const (
+	P2PKHUncompressed AddressType = 0
+	P2PKH             AddressType = 4
+	P2SHP2WPKH        AddressType = 8
+	P2WPKH            AddressType = 12
+)
+
+// Hash returns the double SHA-256 of the message with the "Bitcoin Signed Message" prefix
+func Hash(message string) []byte {
+	data := []byte(magic)
+	data = append(data, compactSize(len(message))...)
+	data = append(data, message...)
+	return crypto.Hash256(data)
+}
+
+// Sign returns the base64 compact recoverable signature (BIP137) of a message
+func Sign(priv *keys.PrivateKey, message string, addrType AddressType) (string, error) {
+	if addrType != P2PKHUncompressed && addrType != P2PKH && addrType != P2SHP2WPKH && addrType != P2WPKH {
+		return "", fmt.Errorf("invalid address type: %d", addrType)
+	}
+
+	if err := keys.CheckPrivateKey(priv.PrivateKey()); err != nil {
+		return "", err
+	}
+	key := make([]byte, 32)
+	priv.PrivateKey().FillBytes(key)
+	secPrivKey, _ := secp256k1.PrivKeyFromBytes(key)
+
+	signature, err := secp256k1.SignCompact(secPrivKey, Hash(message), addrType != P2PKHUncompressed)
+	if err != nil {
+		return "", err
+	}
+
+	recoveryID := (signature[0] - 27) & 0x03
+	signature[0] = 27 + byte(addrType) + recoveryID
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// Verify recovers the public key of a BIP137 signature and checks it against the address.
+// As in most wallets, a compressed P2PKH header is also accepted for SegWit addresses.
+func Verify(address, message, signature string, params *chaincfg.Params) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != 65 {
+		return errors.New("invalid signature: expected 65 bytes in base64")
+	}
+	if sig[0] < 27 || sig[0] > 42 {
+		return fmt.Errorf("invalid signature header: %d", sig[0])
+	}
+
+	addrType := AddressType((sig[0] - 27) &^ 0x03)
+	recoveryID := (sig[0] - 27) & 0x03
+
+	compact := append([]byte{}, sig...)
+	compact[0] = 27 + recoveryID
+	if addrType != P2PKHUncompressed {
+		compact[0] += 4
+	}
+
+	pubkey, _, err := secp256k1.RecoverCompact(compact, Hash(message))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	candidates := []AddressType{addrType}
+	if addrType == P2PKH {
+		candidates = append(candidates, P2SHP2WPKH, P2WPKH)
+	}
+
+	for _, candidate := range candidates {
+		if pubkeyAddress(pubkey, candidate, params) == address {
+			return nil
+		}
+	}
+	return errors.New("signature does not match the address")
+}
+
+func pubkeyAddress(pubkey *secp256k1.PublicKey, addrType AddressType, params *chaincfg.Params) string {
+	switch addrType {
+	case P2PKHUncompressed:
+		return base58.CheckEncode([]byte{params.PubKeyHashAddrID}, crypto.Hash160(pubkey.SerializeUncompressed()))
+	case P2PKH:
+		return base58.CheckEncode([]byte{params.PubKeyHashAddrID}, crypto.Hash160(pubkey.SerializeCompressed()))
+	case P2SHP2WPKH:
+		redeem := script.WitnessProgram(0, crypto.Hash160(pubkey.SerializeCompressed()))
+		return base58.CheckEncode([]byte{params.ScriptHashAddrID}, crypto.Hash160(redeem))
+	default:
+		hash := crypto.Hash160(pubkey.SerializeCompressed())
+		program := make([]int, len(hash))
+		for i, b := range hash {
+			program[i] = int(b)
+		}
+
+		addr, _ := bech32.SegwitAddrEncode(params.Bech32HRP, 0, program)
+		return addr
+	}
+}
+
+func compactSize(n int) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		return []byte{0xfd, byte(n), byte(n >> 8)}
+	default:
+		return []byte{0xfe, byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+	}
+}
@@ -0,0 +1,67 @@
+package message_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/message"
+)
+
+var privateKey = keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+
+type testData struct {
+	addrType  message.AddressType
+	address   string
+	signature string
+}
+
+var tests = []testData{
+	{message.P2PKHUncompressed, privateKey.ToAddressLegacyUncompressed(), "HGXH085B9ZEWwQqpO/zC9gtJZVES7DgLOHPONO5mbvCqXPI91aSz+/pYk/HK4w6NSYuzxgRi3qmNs/bTz9Pjr1o="},
+	{message.P2PKH, privateKey.ToAddressLegacy(), "IGXH085B9ZEWwQqpO/zC9gtJZVES7DgLOHPONO5mbvCqXPI91aSz+/pYk/HK4w6NSYuzxgRi3qmNs/bTz9Pjr1o="},
+	{message.P2SHP2WPKH, privateKey.ToAddressSegWitCompat(), "JGXH085B9ZEWwQqpO/zC9gtJZVES7DgLOHPONO5mbvCqXPI91aSz+/pYk/HK4w6NSYuzxgRi3qmNs/bTz9Pjr1o="},
+	{message.P2WPKH, privateKey.ToAddressSegWit(), "KGXH085B9ZEWwQqpO/zC9gtJZVES7DgLOHPONO5mbvCqXPI91aSz+/pYk/HK4w6NSYuzxgRi3qmNs/bTz9Pjr1o="},
+}
+
+func TestHash(t *testing.T) {
+	expected := "a7af0baad5ae99b97fc69b3a0d1abcf3ef17f131cc4776e1bc11933ec8550f49"
+	if result := hex.EncodeToString(message.Hash("Hello World")); result != expected {
+		t.Errorf("Hash FAILED. Expected %s, got %s\n", expected, result)
+	} else {
+		t.Logf("Hash passed: %s\n", result)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, test := range tests {
+		signature, err := message.Sign(&privateKey, "Hello World", test.addrType)
+		switch {
+		case err != nil:
+			t.Errorf("Sign for %s FAILED: %v\n", test.address, err)
+			continue
+		case signature != test.signature:
+			t.Errorf("Sign for %s FAILED. Expected %s, got %s\n", test.address, test.signature, signature)
+		}
+
+		if err := message.Verify(test.address, "Hello World", signature, chaincfg.MainNet); err != nil {
+			t.Errorf("Verify for %s FAILED: %v\n", test.address, err)
+		} else {
+			t.Logf("Verify passed: %s, %s\n", test.address, signature)
+		}
+
+		if err := message.Verify(test.address, "Hello World!", signature, chaincfg.MainNet); err == nil {
+			t.Errorf("Verify of another message for %s passed, should've failed: FAIL\n", test.address)
+		}
+	}
+}
+
+func TestVerifyWrongAddress(t *testing.T) {
+	signature, _ := message.Sign(&privateKey, "Hello World", message.P2PKHUncompressed)
+	if err := message.Verify(privateKey.ToAddressLegacy(), "Hello World", signature, chaincfg.MainNet); err == nil {
+		t.Errorf("Verify with another address passed, should've failed: FAIL\n")
+	} else {
+		t.Logf("Verify with another address failed: %v\n", err)
+	}
+}
@@ -0,0 +1,110 @@
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+var wordIndexes = make(map[string]int, len(english))
+
+func init() {
+	for i, word := range english {
+		wordIndexes[word] = i
+	}
+}
+
+// Normalize applies NFKD, lowercases the phrase and collapses whitespace between words
+func Normalize(phrase string) string {
+	return strings.Join(strings.Fields(strings.ToLower(norm.NFKD.String(phrase))), " ")
+}
+
+// Validate checks the phrase has a valid word count, known words and a matching checksum.
+func Validate(phrase string) error {
+	_, err := ToEntropy(phrase)
+	return err
+}
+
+// ToEntropy returns the entropy encoded by the phrase, verifying its checksum.
+func ToEntropy(phrase string) ([]byte, error) {
+	words := strings.Fields(Normalize(phrase))
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("invalid mnemonic length: %d words", len(words))
+	}
+
+	total := new(big.Int)
+	for _, word := range words {
+		index, ok := wordIndexes[word]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word: %s", word)
+		}
+		total.Lsh(total, 11)
+		total.Or(total, big.NewInt(int64(index)))
+	}
+
+	checksumBits := uint(len(words) * 11 / 33)
+	entropyBytes := len(words) * 11 * 32 / 33 / 8
+
+	checksum := new(big.Int).And(total, big.NewInt(1<<checksumBits-1))
+	total.Rsh(total, checksumBits)
+
+	entropy := make([]byte, entropyBytes)
+	total.FillBytes(entropy)
+
+	if checksum.Cmp(entropyChecksum(entropy)) != 0 {
+		return nil, errors.New("invalid mnemonic checksum")
+	}
+
+	return entropy, nil
+}
+
+// FromEntropy encodes 16, 20, 24, 28 or 32 bytes of entropy as a mnemonic phrase.
+func FromEntropy(entropy []byte) (string, error) {
+	switch len(entropy) {
+	case 16, 20, 24, 28, 32:
+	default:
+		return "", fmt.Errorf("invalid entropy length: %d bytes", len(entropy))
+	}
+
+	checksumBits := uint(len(entropy) / 4)
+	total := new(big.Int).SetBytes(entropy)
+	total.Lsh(total, checksumBits)
+	total.Or(total, entropyChecksum(entropy))
+
+	wordCount := (len(entropy)*8 + int(checksumBits)) / 11
+	words := make([]string, wordCount)
+	mask := big.NewInt(2047)
+	for i := wordCount - 1; i >= 0; i-- {
+		index := new(big.Int).And(total, mask)
+		words[i] = english[index.Int64()]
+		total.Rsh(total, 11)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// ToSeed validates the phrase and returns the 64-byte BIP39 seed.
+// The passphrase is NFKD-normalized, as BIP39 requires.
+func ToSeed(phrase, passphrase string) ([]byte, error) {
+	if err := Validate(phrase); err != nil {
+		return nil, err
+	}
+
+	salt := []byte("mnemonic" + norm.NFKD.String(passphrase))
+	return pbkdf2.Key([]byte(Normalize(phrase)), salt, 2048, 64, sha512.New), nil
+}
+
+func entropyChecksum(entropy []byte) *big.Int {
+	checksumBits := uint(len(entropy) / 4)
+	hash := sha256.Sum256(entropy)
+	checksum := new(big.Int).SetBytes(hash[:])
+	return checksum.Rsh(checksum, 256-checksumBits)
+}
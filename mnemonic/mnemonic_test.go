@@ -0,0 +1,134 @@
+package mnemonic_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ottosch/pick-private/mnemonic"
+)
+
+type testData struct {
+	entropy  string
+	mnemonic string
+	seed     string
+}
+
+// BIP39 test vectors, passphrase "TREZOR"
+var tests = []testData{
+	{
+		entropy:  "00000000000000000000000000000000",
+		mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		entropy:  "ffffffffffffffffffffffffffffffff",
+		mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		seed:     "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		entropy:  "808080808080808080808080808080808080808080808080",
+		mnemonic: "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+		seed:     "107d7c02a5aa6f38c58083ff74f04c607c2d2c0ecc55501dadd72d025b751bc27fe913ffb796f841c49b1d33b610cf0e91d3aa239027f5e99fe4ce9e5088cd65",
+	},
+	{
+		entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		mnemonic: "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+		seed:     "bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87",
+	},
+	{
+		entropy:  "3e141609b97933b66a060dcddc71fad1d91677db872031e85f4c015c5e7e8982",
+		mnemonic: "dignity pass list indicate nasty swamp pool script soccer toe leaf photo multiply desk host tomato cradle drill spread actor shine dismiss champion exotic",
+		seed:     "ff7f3184df8696d8bef94b6c03114dbee0ef89ff938712301d27ed8336ca89ef9635da20af07d4175f2bf5f3de130f39c9d9e8dd0472489c19b1a020a940da67",
+	},
+}
+
+var invalidMnemonics = []string{
+	// bad checksum
+	"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+	// unknown word
+	"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon bitcoin",
+	// invalid word count
+	"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	"",
+}
+
+func TestFromEntropy(t *testing.T) {
+	for _, test := range tests {
+		entropy, _ := hex.DecodeString(test.entropy)
+		result, err := mnemonic.FromEntropy(entropy)
+
+		switch {
+		case err != nil:
+			t.Errorf("FromEntropy for %s FAILED: %v\n", test.entropy, err)
+		case result != test.mnemonic:
+			t.Errorf("FromEntropy for %s FAILED. Expected %s, got %s\n", test.entropy, test.mnemonic, result)
+		default:
+			t.Logf("FromEntropy passed: %s, %s\n", test.entropy, test.mnemonic)
+		}
+	}
+}
+
+func TestToEntropy(t *testing.T) {
+	for _, test := range tests {
+		result, err := mnemonic.ToEntropy(test.mnemonic)
+
+		switch {
+		case err != nil:
+			t.Errorf("ToEntropy for %s FAILED: %v\n", test.mnemonic, err)
+		case hex.EncodeToString(result) != test.entropy:
+			t.Errorf("ToEntropy for %s FAILED. Expected %s, got %x\n", test.mnemonic, test.entropy, result)
+		default:
+			t.Logf("ToEntropy passed: %s, %s\n", test.mnemonic, test.entropy)
+		}
+	}
+}
+
+func TestToSeed(t *testing.T) {
+	for _, test := range tests {
+		result, err := mnemonic.ToSeed(test.mnemonic, "TREZOR")
+
+		switch {
+		case err != nil:
+			t.Errorf("ToSeed for %s FAILED: %v\n", test.mnemonic, err)
+		case hex.EncodeToString(result) != test.seed:
+			t.Errorf("ToSeed for %s FAILED. Expected %s, got %x\n", test.mnemonic, test.seed, result)
+		default:
+			t.Logf("ToSeed passed: %s, %s\n", test.mnemonic, test.seed)
+		}
+	}
+}
+
+func TestToSeedNormalization(t *testing.T) {
+	phrase := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	expected := "2cd11177804a95e88bacb829e7418fa89d51d048b75b61fa1b27996d7dbe51573235e898fe8a2ae430e173d9e951cee7bc3176aeab2a7f0f98997358bea01f27"
+
+	// precomposed, decomposed and fully NFKD forms of the same passphrase
+	for _, passphrase := range []string{"caf\u00e9 \u3342", "cafe\u0301 \u3342", "cafe\u0301 \u30db\u30fc\u30f3"} {
+		result, err := mnemonic.ToSeed(phrase, passphrase)
+		switch {
+		case err != nil:
+			t.Errorf("ToSeed for %q FAILED: %v\n", passphrase, err)
+		case hex.EncodeToString(result) != expected:
+			t.Errorf("ToSeed for %q FAILED. Expected %s, got %x\n", passphrase, expected, result)
+		default:
+			t.Logf("ToSeed passed: %q, %s\n", passphrase, expected)
+		}
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	for _, test := range invalidMnemonics {
+		if err := mnemonic.Validate(test); err == nil {
+			t.Errorf("Validate for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("Validate for %s failed: %v\n", test, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	phrase := "  Abandon abandon ABANDON abandon\tabandon abandon abandon abandon abandon abandon abandon about "
+	if err := mnemonic.Validate(phrase); err != nil {
+		t.Errorf("Validate for %q FAILED: %v\n", phrase, err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ottosch/pick-private/multisig"
+)
+
+func runMultisig(args []string) {
+	flags := flag.NewFlagSet("multisig", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s multisig [options] m key...\n", os.Args[0])
+		fmt.Println("\nKeys are public keys, or private, extended or mnemonic keys (quoted) whose public key is used.")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s multisig 2 02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8 02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f\n", os.Args[0])
+		fmt.Printf("  %s multisig -sorted=false 2 1 2 3\n", os.Args[0])
+	}
+
+	var sorted bool
+	flags.BoolVar(&sorted, "sorted", true, "sort the keys (BIP67, sortedmulti). With -sorted=false the given order is kept (multi)")
+	addKeyFlags(flags)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) < 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	required, err := strconv.Atoi(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid multisig threshold: %s\n", positional[0])
+		os.Exit(1)
+	}
+
+	var pubkeys [][]byte
+	for _, key := range positional[1:] {
+		pubkeys = append(pubkeys, parsePublicKey([]string{key}))
+	}
+
+	ms, err := multisig.New(required, pubkeys, sorted, params)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("[%d-of-%d multisig]\n", required, len(pubkeys))
+	for i, pubkey := range ms.PublicKeys() {
+		fmt.Printf("Key %d: %x\n", i+1, pubkey)
+	}
+	fmt.Printf("Script: %x\n", ms.Script())
+	fmt.Println()
+
+	if ms.P2SH() {
+		fmt.Println("[P2SH]")
+		fmt.Printf("   Address: %s\n", ms.ToAddressP2SH())
+		fmt.Printf("    Script: %s\n", ms.ToScriptP2SH())
+		fmt.Printf("Descriptor: %s\n", ms.DescriptorP2SH())
+		fmt.Println()
+	} else {
+		fmt.Println("Note: no P2SH address, as the script is over the P2SH limits (15 keys, 520 bytes)")
+		fmt.Println()
+	}
+
+	if !params.SegWit {
+		return
+	}
+	if !ms.SegWit() {
+		fmt.Println("Note: no segwit addresses, as segwit scripts need compressed keys")
+		return
+	}
+
+	fmt.Println("[P2SH-P2WSH]")
+	fmt.Printf("   Address: %s\n", ms.ToAddressP2SHP2WSH())
+	fmt.Printf("    Script: %s\n", ms.ToScriptP2SHP2WSH())
+	fmt.Printf("Descriptor: %s\n", ms.DescriptorP2SHP2WSH())
+	fmt.Println()
+
+	fmt.Println("[P2WSH]")
+	fmt.Printf("   Address: %s\n", ms.ToAddressP2WSH())
+	fmt.Printf("    Script: %s\n", ms.ToScriptP2WSH())
+	fmt.Printf("Descriptor: %s\n", ms.DescriptorP2WSH())
+	fmt.Println()
+}
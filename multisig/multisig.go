@@ -0,0 +1,100 @@
+package multisig
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/scripthash"
+)
+
+// Size and key limits: a P2SH redeem script is a push (520 bytes, 15 keys as in sh(multi()));
+// a witness script has the standard P2WSH limit (3600 bytes) and up to 20 keys
+const (
+	maxRedeemScriptSize  = 520
+	maxWitnessScriptSize = 3600
+	maxP2SHKeys          = 15
+	maxKeys              = 20
+)
+
+// Multisig is an m-of-n bare multisig script, with its P2SH, P2SH-P2WSH and P2WSH forms.
+// The keys of a sortedmulti are sorted in the script.
+type Multisig struct {
+	*scripthash.Outputs
+	pubkeys [][]byte
+}
+
+// New creates an m-of-n multisig from compressed or uncompressed public keys.
+// If sorted, the keys are sorted lexicographically (BIP67, sortedmulti).
+func New(required int, pubkeys [][]byte, sorted bool, params *chaincfg.Params) (*Multisig, error) {
+	if len(pubkeys) == 0 || len(pubkeys) > maxKeys {
+		return nil, fmt.Errorf("invalid number of keys: %d (1 to %d)", len(pubkeys), maxKeys)
+	}
+	if required < 1 || required > len(pubkeys) {
+		return nil, fmt.Errorf("invalid multisig threshold: %d of %d", required, len(pubkeys))
+	}
+
+	keys := make([][]byte, len(pubkeys))
+	for i, pubkey := range pubkeys {
+		if len(pubkey) != 33 && len(pubkey) != 65 {
+			return nil, fmt.Errorf("invalid public key length: %d", len(pubkey))
+		}
+		if _, err := secp256k1.ParsePubKey(pubkey); err != nil {
+			return nil, fmt.Errorf("invalid public key %x: %v", pubkey, err)
+		}
+		keys[i] = pubkey
+	}
+
+	if sorted {
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i], keys[j]) < 0
+		})
+	}
+
+	ms := &Multisig{scripthash.New(script.Multisig(required, keys), expression(required, keys, sorted), params), keys}
+	if !ms.P2SH() && (!ms.SegWit() || !params.SegWit) {
+		return nil, fmt.Errorf("%d-of-%d script of %d bytes fits neither P2SH (%d keys, %d bytes) nor P2WSH (compressed keys, %d bytes)",
+			required, len(keys), len(ms.Script()), maxP2SHKeys, maxRedeemScriptSize, maxWitnessScriptSize)
+	}
+	return ms, nil
+}
+
+// expression returns the multi() or sortedmulti() descriptor expression
+func expression(required int, pubkeys [][]byte, sorted bool) string {
+	name := "multi"
+	if sorted {
+		name = "sortedmulti"
+	}
+
+	args := []string{fmt.Sprintf("%d", required)}
+	for _, pubkey := range pubkeys {
+		args = append(args, hex.EncodeToString(pubkey))
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(args, ","))
+}
+
+// PublicKeys returns the public keys, in script order
+func (ms *Multisig) PublicKeys() [][]byte {
+	return ms.pubkeys
+}
+
+// P2SH reports whether the script fits a bare P2SH redeem script
+func (ms *Multisig) P2SH() bool {
+	return len(ms.pubkeys) <= maxP2SHKeys && len(ms.Script()) <= maxRedeemScriptSize
+}
+
+// SegWit reports whether the script can be a witness script (P2WSH and P2SH-P2WSH):
+// all keys compressed, within the witness script size
+func (ms *Multisig) SegWit() bool {
+	for _, pubkey := range ms.pubkeys {
+		if len(pubkey) != 33 {
+			return false
+		}
+	}
+	return len(ms.Script()) <= maxWitnessScriptSize
+}
@@ -0,0 +1,174 @@
+package multisig_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/multisig"
+)
+
+type multisigTestData struct {
+	required         int
+	pubkeys          []string
+	sorted           bool
+	script           string
+	addressP2SH      string
+	addressP2SHP2WSH string
+	scriptP2SHP2WSH  string
+	addressP2WSH     string
+	scriptP2WSH      string
+	descriptorPrefix string
+}
+
+// BIP67 test vector 1, given in reverse order
+var multisigTests = []multisigTestData{
+	{
+		required:         2,
+		pubkeys:          []string{"02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8", "02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f"},
+		sorted:           true,
+		script:           "522102fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f2102ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f852ae",
+		addressP2SH:      "39bgKC7RFbpoCRbtD5KEdkYKtNyhpsNa3Z",
+		addressP2SHP2WSH: "3BBLivaThSP3C31jzmQJiMWBM7BLndaWfh",
+		scriptP2SHP2WSH:  "a914681478df501ac0e04befea54f6184dc575ec9bbe87",
+		addressP2WSH:     "bc1qknwt9mhqpd7hrjrvpqz57zjqk28xlp2h90te6v22en0m3uctnams3pq5ce",
+		scriptP2WSH:      "0020b4dcb2eee00b7d71c86c08054f0a40b28e6f85572bd79d314accdfb8f30b9f77",
+		descriptorPrefix: "sortedmulti(2,",
+	},
+	{
+		required:         1,
+		pubkeys:          []string{"02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8", "02fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f"},
+		sorted:           false,
+		script:           "512102ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f82102fe6f0a5a297eb38c391581c4413e084773ea23954d93f7753db7dc0adc188b2f52ae",
+		descriptorPrefix: "multi(1,02ff",
+	},
+}
+
+func decodeKeys(pubkeys []string) [][]byte {
+	var decoded [][]byte
+	for _, pubkey := range pubkeys {
+		data, _ := hex.DecodeString(pubkey)
+		decoded = append(decoded, data)
+	}
+	return decoded
+}
+
+func TestMultisig(t *testing.T) {
+	for _, test := range multisigTests {
+		ms, err := multisig.New(test.required, decodeKeys(test.pubkeys), test.sorted, chaincfg.MainNet)
+		if err != nil {
+			t.Errorf("New for %d of %v FAILED: %v\n", test.required, test.pubkeys, err)
+			continue
+		}
+
+		if script := hex.EncodeToString(ms.Script()); script != test.script {
+			t.Errorf("Script FAILED. Expected %s, got %s\n", test.script, script)
+		} else {
+			t.Logf("Script passed: %s\n", test.script)
+		}
+
+		if test.addressP2SH != "" {
+			got := []string{ms.ToAddressP2SH(), ms.ToAddressP2SHP2WSH(), ms.ToScriptP2SHP2WSH(), ms.ToAddressP2WSH(), ms.ToScriptP2WSH()}
+			expected := []string{test.addressP2SH, test.addressP2SHP2WSH, test.scriptP2SHP2WSH, test.addressP2WSH, test.scriptP2WSH}
+			for i := range expected {
+				if got[i] != expected[i] {
+					t.Errorf("Address/script FAILED. Expected %s, got %s\n", expected[i], got[i])
+				} else {
+					t.Logf("Address/script passed: %s\n", expected[i])
+				}
+			}
+		}
+
+		// the descriptors must parse back to the same scriptPubKeys
+		descriptors := map[string]string{
+			ms.DescriptorP2SH():      ms.ToScriptP2SH(),
+			ms.DescriptorP2SHP2WSH(): ms.ToScriptP2SHP2WSH(),
+			ms.DescriptorP2WSH():     ms.ToScriptP2WSH(),
+		}
+		for desc, expected := range descriptors {
+			parsed, err := descriptor.Parse(desc)
+			if err != nil {
+				t.Errorf("Descriptor %s FAILED: %v\n", desc, err)
+				continue
+			}
+			script, _ := parsed.Script(0)
+			switch {
+			case hex.EncodeToString(script) != expected:
+				t.Errorf("Descriptor %s FAILED. Expected %s, got %x\n", desc, expected, script)
+			case !strings.Contains(desc, test.descriptorPrefix):
+				t.Errorf("Descriptor %s FAILED. Expected it to contain %s\n", desc, test.descriptorPrefix)
+			default:
+				t.Logf("Descriptor passed: %s\n", desc)
+			}
+		}
+	}
+}
+
+func TestMultisigLimits(t *testing.T) {
+	var pubkeys [][]byte
+	for i := int64(1); i <= 21; i++ {
+		key := keys.FromBigInt(big.NewInt(i), chaincfg.MainNet)
+		pubkeys = append(pubkeys, key.PublicKey())
+	}
+
+	tests := []struct {
+		keys   int
+		p2sh   bool
+		segwit bool
+	}{
+		{15, true, true},
+		{16, false, true},
+		{20, false, true},
+	}
+
+	for _, test := range tests {
+		ms, err := multisig.New(2, pubkeys[:test.keys], true, chaincfg.MainNet)
+		switch {
+		case err != nil:
+			t.Errorf("New for 2 of %d FAILED: %v\n", test.keys, err)
+		case ms.P2SH() != test.p2sh || ms.SegWit() != test.segwit:
+			t.Errorf("New for 2 of %d FAILED. Expected P2SH %t and segwit %t, got %t and %t\n", test.keys, test.p2sh, test.segwit, ms.P2SH(), ms.SegWit())
+		default:
+			if _, err := descriptor.Parse(ms.DescriptorP2WSH()); err != nil {
+				t.Errorf("Descriptor for 2 of %d FAILED: %v\n", test.keys, err)
+			}
+			t.Logf("New passed: 2 of %d keys, %d bytes\n", test.keys, len(ms.Script()))
+		}
+	}
+
+	if _, err := multisig.New(2, pubkeys, true, chaincfg.MainNet); err == nil {
+		t.Errorf("New for 2 of 21 passed, should've failed: FAIL\n")
+	}
+	if _, err := multisig.New(2, pubkeys[:16], true, chaincfg.DogecoinMainNet); err == nil {
+		t.Errorf("New for 2 of 16 without segwit passed, should've failed: FAIL\n")
+	}
+}
+
+func TestMultisigInvalid(t *testing.T) {
+	pubkey := "02ff12471208c14bd580709cb2358d98975247d8765f92bc25eab3b2763ed605f8"
+	uncompressed := "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+
+	tests := []struct {
+		required int
+		pubkeys  []string
+	}{
+		{0, []string{pubkey}},
+		{2, []string{pubkey}},
+		{1, nil},
+		{1, []string{"02fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30"}},
+		{1, []string{pubkey[2:]}},
+		{1, []string{uncompressed, uncompressed, uncompressed, uncompressed, uncompressed, uncompressed, uncompressed, uncompressed}},
+	}
+
+	for _, test := range tests {
+		if _, err := multisig.New(test.required, decodeKeys(test.pubkeys), true, chaincfg.MainNet); err == nil {
+			t.Errorf("New for %d of %v passed, should've failed\n", test.required, test.pubkeys)
+		} else {
+			t.Logf("New passed: %d of %d keys rejected, %v\n", test.required, len(test.pubkeys), err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"encoding/hex"
 	"flag"
 	"fmt"
@@ -10,51 +11,159 @@ import (
 	"strings"
 
 	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bip38"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/hd"
 	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/mnemonic"
+	"github.com/ottosch/pick-private/taproot"
+	"golang.org/x/term"
 )
 
 var (
-	regexDecimal = regexp.MustCompile(`^\d+$`)
-	regexBinary  = regexp.MustCompile(`^[01]+$`)
-	regexHex     = regexp.MustCompile(`^[a-fA-F0-9]+$`)
-	regexWif     = regexp.MustCompile(`^[KL59c][1-9a-km-zA-HJ-NP-Z]+$`)
+	regexDecimal  = regexp.MustCompile(`^\d+$`)
+	regexBinary   = regexp.MustCompile(`^[01]+$`)
+	regexHex      = regexp.MustCompile(`^[a-fA-F0-9]+$`)
+	regexExtended = regexp.MustCompile(`^[xtyzuv](prv|pub)[1-9a-km-zA-HJ-NP-Z]+$`)
+	regexMnemonic = regexp.MustCompile(`^[a-zA-Z]+(\s+[a-zA-Z]+){11,}$`)
+	regexBip38    = regexp.MustCompile(`^6P[1-9a-km-zA-HJ-NP-Z]{56}$`)
+	regexPublic   = regexp.MustCompile(`^(0[23][a-fA-F0-9]{64}|04[a-fA-F0-9]{128})$`)
 
-	testnet    bool
-	keyDecimal bool
-	keyBinary  bool
-	keyHex     bool
-	keyWif     bool
+	keyDecimal  bool
+	keyBinary   bool
+	keyHex      bool
+	keyWif      bool
+	keyExtended bool
+	keyMnemonic bool
+	keyBip38    bool
+	keyPublic   bool
 
-	keyType  string
-	inputKey string
+	keyType        string
+	coinName       string
+	networkName    string
+	inputKey       string
+	derivationPath string
+	passphrase     string
+	tapLeaves      stringList
+	accountIndex   uint
+	accountCount   uint
+	ethereum       bool
+	bip38Encrypt   bool
+	bip38Pass      *string
 
-	privateKey keys.PrivateKey
+	params      *chaincfg.Params
+	privateKey  keys.PrivateKey
+	publicKey   keys.PublicKey
+	extendedKey *hd.ExtendedKey
+	rootKey     *hd.ExtendedKey
+	seed        []byte
+	taprootTree *taproot.Tree
+
+	subcommands = map[string]func(args []string){
+		"address":    runAddress,
+		"bip38":      runBip38,
+		"contract":   runContract,
+		"descriptor": runDescriptor,
+		"multisig":   runMultisig,
+		"script":     runScript,
+		"psbt":       runPsbt,
+		"sign":       runSign,
+		"tx":         runTx,
+		"verify":     runVerify,
+	}
+)
+
+var (
+	coinUsage    = "coin: " + strings.Join(chaincfg.Coins(), ", ")
+	networkUsage = "network: " + strings.Join(chaincfg.Names("btc"), ", ") + " (mainnet or testnet for other coins)"
 )
 
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ", ")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	configCliArgs()
 	parseCliArgs()
-	parsePrivateKey()
+	fmt.Println(parsePrivateKey())
+	fmt.Println()
+
+	if seed != nil {
+		printSeed()
+	}
+
+	if accountCount > 0 {
+		printAccounts()
+		return
+	}
+
+	if extendedKey != nil {
+		printExtendedKey()
+	}
+
 	printOutput()
 }
 
 func configCliArgs() {
 	flag.CommandLine.SetOutput(os.Stdout)
 	flag.Usage = func() {
-		fmt.Printf("Usage: %s [options] private key\n", os.Args[0])
+		fmt.Printf("Usage: %s [options] private key | public key | extended key | mnemonic\n", os.Args[0])
+		fmt.Printf("       %s address address\n", os.Args[0])
+		fmt.Printf("       %s descriptor [options] descriptor\n", os.Args[0])
+		fmt.Printf("       %s bip38 [options] intermediate | encrypt code | confirm code\n", os.Args[0])
+		fmt.Printf("       %s multisig [options] m key...\n", os.Args[0])
+		fmt.Printf("       %s contract [options] timelock key | htlc receiver refund | 2of2 key cosigner\n", os.Args[0])
+		fmt.Printf("       %s script [options] decode hex | assemble asm | eval scriptPubKey\n", os.Args[0])
+		fmt.Printf("       %s sign [options] -m message | -digest digest private key\n", os.Args[0])
+		fmt.Printf("       %s psbt [options] decode | sign | finalize | extract psbt\n", os.Args[0])
+		fmt.Printf("       %s tx [options] -prevout amount:script -key key raw transaction\n", os.Args[0])
+		fmt.Printf("       %s verify [options] -m message address signature | -digest digest public key signature\n", os.Args[0])
 		fmt.Println("\nOptions:")
 		flag.PrintDefaults()
 
 		fmt.Println("\nExamples:")
 		fmt.Printf("  %s 1\n", os.Args[0])
-		fmt.Printf("  %s -testnet deadbeef\n", os.Args[0])
+		fmt.Printf("  %s -network testnet deadbeef\n", os.Args[0])
+		fmt.Printf("  %s -network regtest deadbeef\n", os.Args[0])
 		fmt.Printf("  %s 110001\n", os.Args[0])
-		fmt.Printf("  %s -testnet -type hex 2222\n", os.Args[0])
+		fmt.Printf("  %s -network signet -type hex 2222\n", os.Args[0])
+		fmt.Printf("  %s -coin ltc 1\n", os.Args[0])
+		fmt.Printf("  %s -eth 1\n", os.Args[0])
+		fmt.Printf("  %s -bip38-encrypt 1\n", os.Args[0])
+		fmt.Printf("  echo TestingOneTwoThree | %s 6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo\n", os.Args[0])
 		fmt.Printf("  %s KxR42n9vD54RcZgCvuaDgfbXfRGiJcpSfJMicjmaJzr7V17x5gXP2\n", os.Args[0])
+		fmt.Printf("  %s 0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798\n", os.Args[0])
+		fmt.Printf("  %s -tapleaf 51 -tapleaf c0:0075 1\n", os.Args[0])
+		fmt.Printf("  %s -path \"m/84'/0'/0'/0/5\" xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi\n", os.Args[0])
+		fmt.Printf("  %s -path \"m/84'/0'/0'/0/0\" abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n", os.Args[0])
+		fmt.Printf("  %s -accounts 5 abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n", os.Args[0])
 
 	}
-	flag.BoolVar(&testnet, "testnet", false, "generate testnet instead of mainnet")
-	flag.StringVar(&keyType, "type", "", "force input into a specific type. Possible values: decimal [d], binary [b], hex [h] or wif [w]")
+	flag.StringVar(&coinName, "coin", "btc", coinUsage)
+	flag.StringVar(&networkName, "network", "", networkUsage+". Defaults to mainnet, or to the network of an extended key")
+	flag.StringVar(&keyType, "type", "", "force input into a specific type. Possible values: decimal [d], binary [b], hex [h], wif [w], extended [x], mnemonic [m], bip38 [e] or public key [p]")
+	flag.StringVar(&derivationPath, "path", "", "derivation path for an extended key or mnemonic input, like m/84'/0'/0'/0/5")
+	flag.StringVar(&passphrase, "passphrase", "", "optional BIP39 passphrase for a mnemonic input")
+	flag.UintVar(&accountCount, "accounts", 0, "print the BIP44/49/84/86 accounts of a mnemonic or master key, with this many receive and change addresses")
+	flag.UintVar(&accountIndex, "account", 0, "account index used by -accounts")
+	flag.BoolVar(&bip38Encrypt, "bip38-encrypt", false, "also print the BIP38 encrypted keys. The passphrase is prompted for, or read from stdin")
+	flag.BoolVar(&ethereum, "eth", false, "also print the Ethereum (EIP-55) address of the key")
+	flag.Var(&tapLeaves, "tapleaf", "add a tapscript leaf to the Taproot script tree, as script hex or version:script hex. Can be repeated")
 	flag.Parse()
 
 	if flag.NArg() == 0 {
@@ -63,7 +172,49 @@ func configCliArgs() {
 	}
 }
 
+// parseInterspersed parses a subcommand's flags, which may come before or after
+// its positional arguments, and returns the positional arguments.
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// lookupNetwork returns the chain params for -coin and -network values, exiting if unknown.
+func lookupNetwork(coin, name string) *chaincfg.Params {
+	params, err := chaincfg.LookupCoin(coin, name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return params
+}
+
+// isWif reports whether s is a WIF private key of the selected network
+func isWif(s string) bool {
+	_, err := base58.Decode(s, params.PrivateKeyID)
+	return err == nil
+}
+
+// networkParams returns the network of the -coin and -network flags
+func networkParams() *chaincfg.Params {
+	if networkName == "" {
+		return lookupNetwork(coinName, "mainnet")
+	}
+	return lookupNetwork(coinName, networkName)
+}
+
 func parseCliArgs() {
+	params = networkParams()
+
 	keyType = strings.ToLower(keyType)
 	switch {
 	case keyType == "decimal" || keyType == "d":
@@ -74,6 +225,14 @@ func parseCliArgs() {
 		keyHex = true
 	case keyType == "wif" || keyType == "w":
 		keyWif = true
+	case keyType == "extended" || keyType == "x":
+		keyExtended = true
+	case keyType == "mnemonic" || keyType == "m":
+		keyMnemonic = true
+	case keyType == "bip38" || keyType == "e":
+		keyBip38 = true
+	case keyType == "public" || keyType == "p":
+		keyPublic = true
 	case keyType == "":
 		break
 	default:
@@ -81,23 +240,65 @@ func parseCliArgs() {
 		os.Exit(1)
 	}
 
-	inputKey = flag.Arg(0)
+	if len(tapLeaves) > 0 {
+		if !params.Taproot {
+			fmt.Fprintf(os.Stderr, "-tapleaf: %s has no Taproot\n", params.Coin)
+			os.Exit(1)
+		}
+
+		var leaves []taproot.Leaf
+		for _, s := range tapLeaves {
+			leaf, err := taproot.ParseLeaf(s)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			leaves = append(leaves, leaf)
+		}
+
+		var err error
+		if taprootTree, err = taproot.NewTree(leaves); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	inputKey = strings.Join(flag.Args(), " ")
 }
 
-func parsePrivateKey() {
-	if !keyDecimal && !keyBinary && !keyHex && !keyWif {
+// parsePrivateKey parses inputKey into the key globals and returns a note on how it was read
+func parsePrivateKey() string {
+	if !keyDecimal && !keyBinary && !keyHex && !keyWif && !keyExtended && !keyMnemonic && !keyBip38 && !keyPublic {
 		switch {
+		case regexBip38.MatchString(inputKey):
+			keyBip38 = true
+		case regexPublic.MatchString(inputKey):
+			keyPublic = true
 		case regexBinary.MatchString(inputKey) && len(inputKey) >= 3:
 			keyBinary = true
 		case regexDecimal.MatchString(inputKey):
 			keyDecimal = true
 		case regexHex.MatchString(inputKey):
 			keyHex = true
-		case regexWif.MatchString(inputKey):
+		case isWif(inputKey):
 			keyWif = true
+		case regexExtended.MatchString(inputKey):
+			keyExtended = true
+		case regexMnemonic.MatchString(inputKey):
+			keyMnemonic = true
 		}
 	}
 
+	if derivationPath != "" && !keyExtended && !keyMnemonic {
+		fmt.Fprintln(os.Stderr, "-path requires an extended key or a mnemonic")
+		os.Exit(1)
+	}
+
+	if accountCount > 0 && (derivationPath != "" || !keyExtended && !keyMnemonic) {
+		fmt.Fprintln(os.Stderr, "-accounts requires a mnemonic or master key, without -path")
+		os.Exit(1)
+	}
+
 	var bigIntKey *big.Int
 	note := "Note: treating input key as "
 	switch {
@@ -110,67 +311,309 @@ func parsePrivateKey() {
 	case keyHex:
 		bigIntKey, _ = new(big.Int).SetString(inputKey, 16)
 		note += "hex"
-	case keyWif || regexWif.MatchString(inputKey):
+	case keyWif:
 		var err error
-		if bigIntKey, err = base58.Decode(inputKey); err != nil {
+		if bigIntKey, err = base58.Decode(inputKey, params.PrivateKeyID); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
 		note += "WIF"
+	case keyExtended:
+		parseExtendedKey()
+		note += "extended key"
+	case keyMnemonic:
+		parseMnemonic()
+		note += "mnemonic"
+	case keyBip38:
+		var err error
+		if privateKey, _, err = bip38.Decrypt(inputKey, readPassphrase("BIP38 passphrase: "), params); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		note += "BIP38 encrypted key"
+	case keyPublic:
+		var err error
+		if publicKey, err = keys.PublicKeyFromHex(inputKey, params); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		note += "public key (watch-only)"
 	default:
 		fmt.Fprintf(os.Stderr, "invalid private key: %s\n", inputKey)
 		os.Exit(1)
 	}
 
-	fmt.Println(note)
+	if extendedKey != nil {
+		if extendedKey.IsPrivate() {
+			privateKey, _ = extendedKey.PrivateKey()
+			return note
+		}
+		keyPublic = true
+		publicKey, _ = keys.PublicKeyFromBytes(extendedKey.PublicKey(), extendedKey.Params())
+	}
+	if keyPublic {
+		if bip38Encrypt {
+			fmt.Fprintln(os.Stderr, "-bip38-encrypt requires a private key")
+			os.Exit(1)
+		}
+		return note
+	}
+	if !keyBip38 {
+		if err := keys.CheckPrivateKey(bigIntKey); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		privateKey = keys.FromBigInt(bigIntKey, params)
+	}
+	return note
+}
+
+// readPassphrase reads a passphrase line from stdin, prompting on stderr without echo if stdin is a terminal.
+// It is read once and reused for both decryption and encryption.
+func readPassphrase(prompt string) string {
+	if bip38Pass != nil {
+		return *bip38Pass
+	}
+
+	var passphrase string
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		input, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "could not read passphrase:", err)
+			os.Exit(1)
+		}
+		passphrase = string(input)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(os.Stderr, "could not read passphrase:", err)
+			os.Exit(1)
+		}
+		passphrase = strings.TrimRight(line, "\r\n")
+	}
+
+	bip38Pass = &passphrase
+	return passphrase
+}
+
+func parseExtendedKey() {
+	var err error
+	if rootKey, err = hd.Parse(inputKey); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if networkName != "" || params != chaincfg.MainNet {
+		if networkName == "" && rootKey.Params().Testnet {
+			params = lookupNetwork(coinName, "testnet")
+		}
+		if rootKey, err = rootKey.WithParams(params); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if extendedKey, err = rootKey.Derive(derivationPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func parseMnemonic() {
+	var err error
+	if seed, err = mnemonic.ToSeed(inputKey, passphrase); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if rootKey, err = hd.NewMaster(seed, params); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if extendedKey, err = rootKey.Derive(derivationPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printSeed() {
+	fmt.Println("[BIP39 seed]")
+	fmt.Println(hex.EncodeToString(seed))
 	fmt.Println()
-	privateKey = keys.FromBigInt(bigIntKey, testnet)
 }
 
-func printOutput() {
-	fmt.Println("[Raw private key]")
-	fmt.Println("Hex:")
-	fmt.Println(fmt.Sprintf("%064x", privateKey.PrivateKey()))
-	fmt.Println("Binary:")
-	fmt.Println(fmt.Sprintf("%b", privateKey.PrivateKey()))
-	fmt.Println("Decimal:")
-	fmt.Println(privateKey.PrivateKey())
+func printExtendedKey() {
+	indexes, _ := hd.ParsePath(derivationPath)
+
+	fmt.Println("[Extended key]")
+	fmt.Printf("              Path: %s\n", hd.FormatPath(indexes))
+	fmt.Printf("             Depth: %d\n", extendedKey.Depth())
+	fmt.Printf("Parent fingerprint: %x\n", extendedKey.ParentFingerprint())
+	fmt.Printf("       Fingerprint: %x\n", extendedKey.Fingerprint())
+	fmt.Printf("      Child number: %s\n", formatChildNumber(extendedKey.ChildNumber()))
+	fmt.Printf("        Chain code: %x\n", extendedKey.ChainCode())
+	if extendedKey.IsPrivate() {
+		fmt.Printf("       Private key: %s\n", extendedKey)
+	}
+	fmt.Printf("        Public key: %s\n", extendedKey.Neuter())
 	fmt.Println()
+}
+
+func formatChildNumber(index uint32) string {
+	if index >= hd.HardenedOffset {
+		return fmt.Sprintf("%d'", index-hd.HardenedOffset)
+	}
+	return fmt.Sprintf("%d", index)
+}
+
+func printOutput() {
+	pub := publicKey
+	if !keyPublic {
+		pub = privateKey.Public()
+
+		fmt.Println("[Raw private key]")
+		fmt.Println("Hex:")
+		fmt.Println(fmt.Sprintf("%064x", privateKey.PrivateKey()))
+		fmt.Println("Binary:")
+		fmt.Println(fmt.Sprintf("%b", privateKey.PrivateKey()))
+		fmt.Println("Decimal:")
+		fmt.Println(privateKey.PrivateKey())
+		fmt.Println()
+	}
 
 	fmt.Println("[Public key]")
 	fmt.Println("Uncompressed:")
-	fmt.Println(hex.EncodeToString(privateKey.PublicKeyUncompressed()))
+	fmt.Println(hex.EncodeToString(pub.PublicKeyUncompressed()))
 	fmt.Println("Hash:")
-	fmt.Println(hex.EncodeToString(privateKey.ToPublicKeyHashUncompressed()))
+	fmt.Println(hex.EncodeToString(pub.ToPublicKeyHashUncompressed()))
 	fmt.Println()
 
 	fmt.Println("Compressed:")
-	fmt.Println(hex.EncodeToString(privateKey.PublicKey()))
+	fmt.Println(hex.EncodeToString(pub.PublicKey()))
 	fmt.Println("Hash:")
-	fmt.Println(hex.EncodeToString(privateKey.ToPublicKeyHash()))
+	fmt.Println(hex.EncodeToString(pub.ToPublicKeyHash()))
 	fmt.Println()
 
 	fmt.Println("[Legacy uncompressed]")
-	fmt.Printf("Address: %s\n", privateKey.ToAddressLegacyUncompressed())
-	fmt.Printf("Privkey: %s\n", privateKey.ToWIFUncompressed())
-	fmt.Printf(" Script: %s\n", privateKey.ToScriptLegacyUncompressed())
+	fmt.Printf("Address: %s\n", pub.ToAddressLegacyUncompressed())
+	printPrivateLine("Privkey", privateKey.ToWIFUncompressed)
+	fmt.Printf(" Script: %s\n", pub.ToScriptLegacyUncompressed())
+	fmt.Printf("    ASM: %s\n", scriptAsm(pub.ToScriptLegacyUncompressed()))
+	fmt.Printf("Pubdesc: %s\n", descriptor.LegacyUncompressed(&pub, false))
+	printPrivateLine("Prvdesc", func() string { return descriptor.LegacyUncompressed(&privateKey, true) })
 	fmt.Println()
 
 	fmt.Println("[Legacy compressed]")
-	fmt.Printf("Address: %s\n", privateKey.ToAddressLegacy())
-	fmt.Printf("Privkey: %s\n", privateKey.ToWIF())
-	fmt.Printf(" Script: %s\n", privateKey.ToScriptLegacy())
+	fmt.Printf("Address: %s\n", pub.ToAddressLegacy())
+	printPrivateLine("Privkey", privateKey.ToWIF)
+	fmt.Printf(" Script: %s\n", pub.ToScriptLegacy())
+	fmt.Printf("    ASM: %s\n", scriptAsm(pub.ToScriptLegacy()))
+	fmt.Printf("Pubdesc: %s\n", descriptor.Legacy(&pub, false))
+	printPrivateLine("Prvdesc", func() string { return descriptor.Legacy(&privateKey, true) })
 	fmt.Println()
 
-	fmt.Println("[P2SH-Segwit]")
-	fmt.Printf("Address: %s\n", privateKey.ToAddressSegWitCompat())
-	fmt.Printf("Privkey: %s\n", privateKey.ToWIF())
-	fmt.Printf(" Script: %s\n", privateKey.ToScriptSegwitCompat())
+	if pub.Params().CashAddrPrefix != "" {
+		cashAddr, _ := pub.ToAddressCashAddr()
+		cashAddrUncompressed, _ := pub.ToAddressCashAddrUncompressed()
+		fmt.Println("[CashAddr]")
+		fmt.Printf("Uncompressed: %s\n", cashAddrUncompressed)
+		fmt.Printf("  Compressed: %s\n", cashAddr)
+		fmt.Println()
+	}
+
+	if pub.Params().SegWit {
+		fmt.Println("[P2SH-Segwit]")
+		fmt.Printf("Address: %s\n", pub.ToAddressSegWitCompat())
+		printPrivateLine("Privkey", privateKey.ToWIF)
+		fmt.Printf(" Script: %s\n", pub.ToScriptSegwitCompat())
+		fmt.Printf("    ASM: %s\n", scriptAsm(pub.ToScriptSegwitCompat()))
+		fmt.Printf("Pubdesc: %s\n", descriptor.SegWitCompat(&pub, false))
+		printPrivateLine("Prvdesc", func() string { return descriptor.SegWitCompat(&privateKey, true) })
+		fmt.Println()
+
+		fmt.Println("[SegWit]")
+		fmt.Printf("Address: %s\n", pub.ToAddressSegWit())
+		printPrivateLine("Privkey", privateKey.ToWIF)
+		fmt.Printf(" Script: %s\n", pub.ToScriptSegwit())
+		fmt.Printf("    ASM: %s\n", scriptAsm(pub.ToScriptSegwit()))
+		fmt.Printf("Pubdesc: %s\n", descriptor.SegWit(&pub, false))
+		printPrivateLine("Prvdesc", func() string { return descriptor.SegWit(&privateKey, true) })
+		fmt.Println()
+	}
+
+	if pub.Params().Taproot {
+		fmt.Println("[Taproot]")
+		fmt.Printf("Address: %s\n", pub.ToAddressTaproot())
+		printPrivateLine("Privkey", privateKey.ToWIF)
+		fmt.Printf(" Script: %s\n", pub.ToScriptTaproot())
+		fmt.Printf("    ASM: %s\n", scriptAsm(pub.ToScriptTaproot()))
+		fmt.Printf("Pubdesc: %s\n", descriptor.Taproot(&pub, false))
+		printPrivateLine("Prvdesc", func() string { return descriptor.Taproot(&privateKey, true) })
+		fmt.Println()
+	}
+
+	if bip38Encrypt {
+		printBip38()
+	}
+
+	if ethereum {
+		fmt.Println("[Ethereum]")
+		fmt.Printf("Address: %s\n", pub.ToAddressEthereum())
+		printPrivateLine("Privkey", func() string { return fmt.Sprintf("0x%064x", privateKey.PrivateKey()) })
+		fmt.Println()
+	}
+
+	if taprootTree != nil {
+		printTaprootTree(&pub)
+	}
+}
+
+// printPrivateLine prints a labelled private value, unless the input is a watch-only public key
+func printPrivateLine(label string, value func() string) {
+	if !keyPublic {
+		fmt.Printf("%s: %s\n", label, value())
+	}
+}
+
+func printBip38() {
+	passphrase := readPassphrase("BIP38 passphrase: ")
+	fmt.Println("[BIP38]")
+	for _, compressed := range []bool{false, true} {
+		encrypted, err := bip38.Encrypt(&privateKey, passphrase, compressed)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if compressed {
+			fmt.Printf("  Compressed: %s\n", encrypted)
+		} else {
+			fmt.Printf("Uncompressed: %s\n", encrypted)
+		}
+	}
 	fmt.Println()
+}
 
-	fmt.Println("[SegWit]")
-	fmt.Printf("Address: %s\n", privateKey.ToAddressSegWit())
-	fmt.Printf("Privkey: %s\n", privateKey.ToWIF())
-	fmt.Printf(" Script: %s\n", privateKey.ToScriptSegwit())
+func printTaprootTree(pub *keys.PublicKey) {
+	fmt.Println("[Taproot script tree]")
+	fmt.Printf("Internal key: %s\n", hex.EncodeToString(pub.TaprootInternalKey()))
+	fmt.Printf(" Merkle root: %s\n", hex.EncodeToString(taprootTree.Root()))
+	fmt.Printf("  Output key: %s\n", hex.EncodeToString(pub.TaprootOutputKeyTree(taprootTree)))
+	fmt.Printf("     Address: %s\n", pub.ToAddressTaprootTree(taprootTree))
+	fmt.Printf("      Script: %s\n", pub.ToScriptTaprootTree(taprootTree))
+	fmt.Printf("         ASM: %s\n", scriptAsm(pub.ToScriptTaprootTree(taprootTree)))
 	fmt.Println()
+
+	for i, leaf := range taprootTree.Leaves() {
+		fmt.Printf("Leaf %d:\n", i)
+		fmt.Printf("      Version: %02x\n", leaf.Version)
+		fmt.Printf("       Script: %s\n", hex.EncodeToString(leaf.Script))
+		fmt.Printf("          ASM: %s\n", scriptAsm(hex.EncodeToString(leaf.Script)))
+		fmt.Printf("         Hash: %s\n", hex.EncodeToString(leaf.Hash()))
+		fmt.Printf("Control block: %s\n", hex.EncodeToString(pub.TaprootControlBlock(taprootTree, i)))
+		fmt.Println()
+	}
 }
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/psbt"
+	"github.com/ottosch/pick-private/tx"
+)
+
+func runPsbt(args []string) {
+	flags := flag.NewFlagSet("psbt", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s psbt [options] decode | sign | finalize | extract psbt\n", os.Args[0])
+		fmt.Println("\nPSBTs (BIP174 and BIP370) are read as base64 or hex. sign adds signatures for the")
+		fmt.Println("P2PKH, P2SH-P2WPKH, P2WPKH and P2TR inputs of the keys; extract prints the network transaction.")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s psbt decode cHNidP8BAHUCAAAAAS...\n", os.Args[0])
+		fmt.Printf("  %s psbt sign -key eb696a065ef48a2192da5b28b694f87544b30fae8327c4510137a922f32c6dcf -finalize cHNidP8BAHUCAAAAAS...\n", os.Args[0])
+	}
+
+	var keyFlags stringList
+	var finalize bool
+	var sigHashFlag string
+	flags.Var(&keyFlags, "key", "private key to sign with, in any format the main command takes. Can be repeated")
+	flags.BoolVar(&finalize, "finalize", false, "finalize the inputs after signing")
+	flags.StringVar(&sigHashFlag, "sighash", "all", "sighash type the inputs may ask for: all, none, single, or one of them with -anyonecanpay, like all-anyonecanpay")
+	addKeyFlags(flags)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	p, err := psbt.Decode(positional[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	switch positional[0] {
+	case "decode":
+		params = networkParams()
+		printPsbt(p)
+
+	case "sign":
+		if len(keyFlags) == 0 {
+			fmt.Fprintln(os.Stderr, "psbt sign needs at least one -key")
+			os.Exit(1)
+		}
+
+		hashType, ok := sigHashTypes[strings.ToLower(sigHashFlag)]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unrecognized sighash type: %s\n", sigHashFlag)
+			os.Exit(1)
+		}
+
+		var privKeys []keys.PrivateKey
+		for _, key := range keyFlags {
+			parseSigningKey([]string{key})
+			privKeys = append(privKeys, privateKey)
+		}
+
+		signed, err := p.Sign(privKeys, hashType)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("[PSBT]")
+		fmt.Printf("   Signed: %d signatures added\n", len(signed))
+		for _, signature := range signed {
+			fmt.Printf("  Input %d: sighash %s\n", signature.Input, sigHashName(signature.HashType))
+		}
+
+		if finalize {
+			finalizePsbt(p)
+		}
+		fmt.Printf("   Base64: %s\n", p)
+
+	case "finalize":
+		fmt.Println("[PSBT]")
+		finalizePsbt(p)
+		fmt.Printf("   Base64: %s\n", p)
+
+	case "extract":
+		signed, err := p.Extract()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Println("[Transaction]")
+		fmt.Printf("TxID: %x\n", signed.TxID())
+		fmt.Printf(" Hex: %x\n", signed.Serialize())
+
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+}
+
+func finalizePsbt(p *psbt.PSBT) {
+	finalized, err := p.Finalize()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Finalized: %d of %d inputs\n", finalized, len(p.Inputs))
+}
+
+func printPsbt(p *psbt.PSBT) {
+	unsigned, _ := p.UnsignedTx()
+
+	fmt.Println("[PSBT]")
+	fmt.Printf("Version: %d\n", p.Version())
+	fmt.Printf("   TxID: %x\n", unsigned.TxID())
+	fmt.Printf("Inputs: %d, outputs: %d, locktime: %d\n", len(unsigned.Inputs), len(unsigned.Outputs), unsigned.LockTime)
+	printPsbtFields(p.Global, psbt.GlobalTypeName)
+
+	var inputTotal int64
+	knownInputs := true
+	for i, in := range unsigned.Inputs {
+		fmt.Printf("\n[Input %d]\n", i)
+		fmt.Printf("Outpoint: %x:%d\n", tx.Reverse(in.PrevHash), in.PrevIndex)
+		fmt.Printf("Sequence: %08x\n", in.Sequence)
+		if prevout, err := p.Prevout(i); err == nil {
+			inputTotal += prevout.Value
+			fmt.Printf("  Amount: %d\n", prevout.Value)
+			fmt.Printf("  Script: %x\n", prevout.Script)
+			if addr, err := address.FromScript(prevout.Script, params); err == nil {
+				fmt.Printf(" Address: %s\n", addr)
+			}
+		} else {
+			knownInputs = false
+			fmt.Printf("    UTXO: %v\n", err)
+		}
+		printPsbtFields(p.Inputs[i], psbt.InputTypeName)
+	}
+
+	var outputTotal int64
+	for i, out := range unsigned.Outputs {
+		outputTotal += out.Value
+		fmt.Printf("\n[Output %d]\n", i)
+		fmt.Printf(" Amount: %d\n", out.Value)
+		fmt.Printf(" Script: %x\n", out.Script)
+		if addr, err := address.FromScript(out.Script, params); err == nil {
+			fmt.Printf("Address: %s\n", addr)
+		}
+		printPsbtFields(p.Outputs[i], psbt.OutputTypeName)
+	}
+
+	if knownInputs {
+		fmt.Printf("\nFee: %d\n", inputTotal-outputTotal)
+	}
+}
+
+func printPsbtFields(m psbt.Map, typeName func(byte) string) {
+	if len(m) == 0 {
+		return
+	}
+
+	fmt.Println("Fields:")
+	for _, pair := range m {
+		if len(pair.KeyData) > 0 {
+			fmt.Printf("  %s %x: %x\n", typeName(pair.Type), pair.KeyData, pair.Value)
+		} else {
+			fmt.Printf("  %s: %x\n", typeName(pair.Type), pair.Value)
+		}
+	}
+}
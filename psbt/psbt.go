@@ -0,0 +1,507 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ottosch/pick-private/tx"
+)
+
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Global types (BIP174, BIP370)
+const (
+	GlobalUnsignedTx       byte = 0x00
+	GlobalXpub             byte = 0x01
+	GlobalTxVersion        byte = 0x02
+	GlobalFallbackLockTime byte = 0x03
+	GlobalInputCount       byte = 0x04
+	GlobalOutputCount      byte = 0x05
+	GlobalTxModifiable     byte = 0x06
+	GlobalVersion          byte = 0xfb
+	GlobalProprietary      byte = 0xfc
+)
+
+// Input types (BIP174, BIP370, BIP371)
+const (
+	InputNonWitnessUTXO         byte = 0x00
+	InputWitnessUTXO            byte = 0x01
+	InputPartialSig             byte = 0x02
+	InputSighashType            byte = 0x03
+	InputRedeemScript           byte = 0x04
+	InputWitnessScript          byte = 0x05
+	InputBIP32Derivation        byte = 0x06
+	InputFinalScriptSig         byte = 0x07
+	InputFinalScriptWitness     byte = 0x08
+	InputRipemd160              byte = 0x0a
+	InputSha256                 byte = 0x0b
+	InputHash160                byte = 0x0c
+	InputHash256                byte = 0x0d
+	InputPreviousTxid           byte = 0x0e
+	InputOutputIndex            byte = 0x0f
+	InputSequence               byte = 0x10
+	InputRequiredTimeLockTime   byte = 0x11
+	InputRequiredHeightLockTime byte = 0x12
+	InputTapKeySig              byte = 0x13
+	InputTapScriptSig           byte = 0x14
+	InputTapLeafScript          byte = 0x15
+	InputTapBIP32Derivation     byte = 0x16
+	InputTapInternalKey         byte = 0x17
+	InputTapMerkleRoot          byte = 0x18
+	InputProprietary            byte = 0xfc
+)
+
+// Output types (BIP174, BIP370, BIP371)
+const (
+	OutputRedeemScript       byte = 0x00
+	OutputWitnessScript      byte = 0x01
+	OutputBIP32Derivation    byte = 0x02
+	OutputAmount             byte = 0x03
+	OutputScript             byte = 0x04
+	OutputTapInternalKey     byte = 0x05
+	OutputTapTree            byte = 0x06
+	OutputTapBIP32Derivation byte = 0x07
+	OutputProprietary        byte = 0xfc
+)
+
+// Pair is a key-value pair of a PSBT map. The key is its type followed by the key data.
+type Pair struct {
+	Type    byte
+	KeyData []byte
+	Value   []byte
+}
+
+// Map is a PSBT map: the global map, or the map of an input or output
+type Map []Pair
+
+// Get returns the value of the pair of a type without key data, or nil
+func (m Map) Get(keyType byte) []byte {
+	for _, pair := range m {
+		if pair.Type == keyType && len(pair.KeyData) == 0 {
+			return pair.Value
+		}
+	}
+	return nil
+}
+
+// GetAll returns the pairs of a type
+func (m Map) GetAll(keyType byte) []Pair {
+	var pairs []Pair
+	for _, pair := range m {
+		if pair.Type == keyType {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// Set adds a pair, replacing the one with the same key
+func (m *Map) Set(keyType byte, keyData, value []byte) {
+	for i, pair := range *m {
+		if pair.Type == keyType && bytes.Equal(pair.KeyData, keyData) {
+			(*m)[i].Value = value
+			return
+		}
+	}
+	*m = append(*m, Pair{keyType, keyData, value})
+}
+
+// Delete removes the pairs of a type
+func (m *Map) Delete(keyType byte) {
+	pairs := (*m)[:0]
+	for _, pair := range *m {
+		if pair.Type != keyType {
+			pairs = append(pairs, pair)
+		}
+	}
+	*m = pairs
+}
+
+// PSBT is a partially signed bitcoin transaction, version 0 (BIP174) or 2 (BIP370)
+type PSBT struct {
+	Global  Map
+	Inputs  []Map
+	Outputs []Map
+}
+
+// New creates a version 0 PSBT from an unsigned transaction
+func New(unsigned *tx.Tx) (*PSBT, error) {
+	for i, in := range unsigned.Inputs {
+		if len(in.ScriptSig) > 0 || len(in.Witness) > 0 {
+			return nil, fmt.Errorf("input %d is not unsigned", i)
+		}
+	}
+
+	return &PSBT{
+		Global:  Map{{GlobalUnsignedTx, nil, unsigned.SerializeNoWitness()}},
+		Inputs:  make([]Map, len(unsigned.Inputs)),
+		Outputs: make([]Map, len(unsigned.Outputs)),
+	}, nil
+}
+
+// Decode parses a PSBT given in base64 or hex
+func Decode(s string) (*PSBT, error) {
+	s = strings.TrimSpace(s)
+	if data, err := hex.DecodeString(s); err == nil {
+		return Parse(data)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid PSBT: neither base64 nor hex")
+	}
+	return Parse(data)
+}
+
+// Parse parses a serialized PSBT
+func Parse(data []byte) (*PSBT, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return nil, errors.New("invalid PSBT: bad magic bytes")
+	}
+
+	reader := bytes.NewReader(data[len(magic):])
+	global, err := readMap(reader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PSBT global map: %v", err)
+	}
+
+	p := &PSBT{Global: global}
+	if err := p.checkVersion(); err != nil {
+		return nil, err
+	}
+	inputCount, outputCount, err := p.counts()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < inputCount; i++ {
+		input, err := readMap(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PSBT input %d: %v", i, err)
+		}
+		p.Inputs = append(p.Inputs, input)
+	}
+	for i := 0; i < outputCount; i++ {
+		output, err := readMap(reader)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PSBT output %d: %v", i, err)
+		}
+		p.Outputs = append(p.Outputs, output)
+	}
+
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("invalid PSBT: %d bytes of trailing data", reader.Len())
+	}
+	if _, err := p.UnsignedTx(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Serialize returns the PSBT in binary format
+func (p *PSBT) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.Write(magic)
+	writeMap(&buf, p.Global)
+	for _, input := range p.Inputs {
+		writeMap(&buf, input)
+	}
+	for _, output := range p.Outputs {
+		writeMap(&buf, output)
+	}
+	return buf.Bytes()
+}
+
+// String returns the PSBT in base64
+func (p *PSBT) String() string {
+	return base64.StdEncoding.EncodeToString(p.Serialize())
+}
+
+// Version returns the PSBT version, 0 or 2
+func (p *PSBT) Version() uint32 {
+	if version := p.Global.Get(GlobalVersion); len(version) == 4 {
+		return binary.LittleEndian.Uint32(version)
+	}
+	return 0
+}
+
+// checkVersion accepts the versions BIP174 and BIP370 define: 0 and 2
+func (p *PSBT) checkVersion() error {
+	if version := p.Global.Get(GlobalVersion); version != nil && len(version) != 4 {
+		return fmt.Errorf("invalid PSBT version field: %x", version)
+	}
+	if version := p.Version(); version != 0 && version != 2 {
+		return fmt.Errorf("unsupported PSBT version: %d", version)
+	}
+	return nil
+}
+
+// UnsignedTx returns the unsigned transaction: the global one of a version 0 PSBT,
+// or the one built from the input and output fields of a version 2 PSBT.
+func (p *PSBT) UnsignedTx() (*tx.Tx, error) {
+	if err := p.checkVersion(); err != nil {
+		return nil, err
+	}
+	if p.Version() == 0 {
+		unsigned, err := tx.Deserialize(p.Global.Get(GlobalUnsignedTx))
+		if err != nil {
+			return nil, fmt.Errorf("invalid PSBT unsigned transaction: %v", err)
+		}
+		return unsigned, nil
+	}
+
+	version, err := uint32Field(p.Global, GlobalTxVersion, true)
+	if err != nil {
+		return nil, err
+	}
+	unsigned := &tx.Tx{Version: int32(version)}
+
+	lockTime, err := uint32Field(p.Global, GlobalFallbackLockTime, false)
+	if err != nil {
+		return nil, err
+	}
+	unsigned.LockTime = lockTime
+
+	for i, input := range p.Inputs {
+		prevHash := input.Get(InputPreviousTxid)
+		if len(prevHash) != 32 {
+			return nil, fmt.Errorf("invalid PSBT input %d: missing previous txid", i)
+		}
+		index, err := uint32Field(input, InputOutputIndex, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PSBT input %d: %v", i, err)
+		}
+
+		sequence := uint32(0xffffffff)
+		if input.Get(InputSequence) != nil {
+			if sequence, err = uint32Field(input, InputSequence, true); err != nil {
+				return nil, fmt.Errorf("invalid PSBT input %d: %v", i, err)
+			}
+		}
+
+		unsigned.Inputs = append(unsigned.Inputs, tx.Input{PrevHash: prevHash, PrevIndex: index, Sequence: sequence})
+	}
+
+	for i, output := range p.Outputs {
+		amount := output.Get(OutputAmount)
+		script := output.Get(OutputScript)
+		if len(amount) != 8 || script == nil {
+			return nil, fmt.Errorf("invalid PSBT output %d: missing amount or script", i)
+		}
+		unsigned.Outputs = append(unsigned.Outputs, tx.Output{Value: int64(binary.LittleEndian.Uint64(amount)), Script: script})
+	}
+
+	return unsigned, nil
+}
+
+// Prevout returns the output spent by an input, from its witness or non-witness UTXO
+func (p *PSBT) Prevout(index int) (tx.Output, error) {
+	input := p.Inputs[index]
+	if utxo := input.Get(InputWitnessUTXO); utxo != nil {
+		reader := bytes.NewReader(utxo)
+		var out tx.Output
+		if err := binary.Read(reader, binary.LittleEndian, &out.Value); err != nil {
+			return tx.Output{}, errors.New("invalid witness UTXO")
+		}
+
+		size, err := tx.ReadCompactSize(reader)
+		if err != nil || size != reader.Len() {
+			return tx.Output{}, errors.New("invalid witness UTXO")
+		}
+		out.Script = utxo[len(utxo)-size:]
+		return out, nil
+	}
+
+	if utxo := input.Get(InputNonWitnessUTXO); utxo != nil {
+		prevTx, err := tx.Deserialize(utxo)
+		if err != nil {
+			return tx.Output{}, fmt.Errorf("invalid non-witness UTXO: %v", err)
+		}
+
+		unsigned, err := p.UnsignedTx()
+		if err != nil {
+			return tx.Output{}, err
+		}
+		in := unsigned.Inputs[index]
+		if !bytes.Equal(prevTx.Hash(), in.PrevHash) || int(in.PrevIndex) >= len(prevTx.Outputs) {
+			return tx.Output{}, errors.New("non-witness UTXO does not match the input")
+		}
+		return prevTx.Outputs[in.PrevIndex], nil
+	}
+
+	return tx.Output{}, errors.New("missing UTXO")
+}
+
+// Prevouts returns the outputs spent by all inputs
+func (p *PSBT) Prevouts() ([]tx.Output, error) {
+	var prevouts []tx.Output
+	for i := range p.Inputs {
+		prevout, err := p.Prevout(i)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %v", i, err)
+		}
+		prevouts = append(prevouts, prevout)
+	}
+	return prevouts, nil
+}
+
+func (p *PSBT) counts() (int, int, error) {
+	if p.Version() == 0 {
+		unsigned, err := tx.Deserialize(p.Global.Get(GlobalUnsignedTx))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid PSBT unsigned transaction: %v", err)
+		}
+		return len(unsigned.Inputs), len(unsigned.Outputs), nil
+	}
+
+	inputCount, err1 := tx.ReadCompactSize(bytes.NewReader(p.Global.Get(GlobalInputCount)))
+	outputCount, err2 := tx.ReadCompactSize(bytes.NewReader(p.Global.Get(GlobalOutputCount)))
+	if err1 != nil || err2 != nil {
+		return 0, 0, errors.New("invalid PSBT: missing input or output count")
+	}
+	return inputCount, outputCount, nil
+}
+
+func uint32Field(m Map, keyType byte, required bool) (uint32, error) {
+	value := m.Get(keyType)
+	switch {
+	case value == nil && !required:
+		return 0, nil
+	case len(value) != 4:
+		return 0, fmt.Errorf("invalid PSBT field %02x", keyType)
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+func readMap(reader *bytes.Reader) (Map, error) {
+	var m Map
+	for {
+		keyLen, err := tx.ReadCompactSize(reader)
+		if err != nil {
+			return nil, err
+		}
+		if keyLen == 0 {
+			return m, nil
+		}
+
+		key, err := readN(reader, keyLen)
+		if err != nil {
+			return nil, err
+		}
+		valueLen, err := tx.ReadCompactSize(reader)
+		if err != nil {
+			return nil, err
+		}
+		value, err := readN(reader, valueLen)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pair := range m {
+			if pair.Type == key[0] && bytes.Equal(pair.KeyData, key[1:]) {
+				return nil, fmt.Errorf("duplicate key %x", key)
+			}
+		}
+		m = append(m, Pair{key[0], key[1:], value})
+	}
+}
+
+func readN(reader *bytes.Reader, n int) ([]byte, error) {
+	if n > reader.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	data := make([]byte, n)
+	_, err := io.ReadFull(reader, data)
+	return data, err
+}
+
+func writeMap(buf *bytes.Buffer, m Map) {
+	for _, pair := range m {
+		key := append([]byte{pair.Type}, pair.KeyData...)
+		buf.Write(tx.CompactSize(len(key)))
+		buf.Write(key)
+		buf.Write(tx.CompactSize(len(pair.Value)))
+		buf.Write(pair.Value)
+	}
+	buf.WriteByte(0x00)
+}
+
+var globalNames = map[byte]string{
+	GlobalUnsignedTx:       "unsigned tx",
+	GlobalXpub:             "xpub",
+	GlobalTxVersion:        "tx version",
+	GlobalFallbackLockTime: "fallback locktime",
+	GlobalInputCount:       "input count",
+	GlobalOutputCount:      "output count",
+	GlobalTxModifiable:     "tx modifiable",
+	GlobalVersion:          "version",
+	GlobalProprietary:      "proprietary",
+}
+
+var inputNames = map[byte]string{
+	InputNonWitnessUTXO:         "non-witness utxo",
+	InputWitnessUTXO:            "witness utxo",
+	InputPartialSig:             "partial sig",
+	InputSighashType:            "sighash type",
+	InputRedeemScript:           "redeem script",
+	InputWitnessScript:          "witness script",
+	InputBIP32Derivation:        "bip32 derivation",
+	InputFinalScriptSig:         "final scriptsig",
+	InputFinalScriptWitness:     "final scriptwitness",
+	InputRipemd160:              "ripemd160 preimage",
+	InputSha256:                 "sha256 preimage",
+	InputHash160:                "hash160 preimage",
+	InputHash256:                "hash256 preimage",
+	InputPreviousTxid:           "previous txid",
+	InputOutputIndex:            "output index",
+	InputSequence:               "sequence",
+	InputRequiredTimeLockTime:   "required time locktime",
+	InputRequiredHeightLockTime: "required height locktime",
+	InputTapKeySig:              "tap key sig",
+	InputTapScriptSig:           "tap script sig",
+	InputTapLeafScript:          "tap leaf script",
+	InputTapBIP32Derivation:     "tap bip32 derivation",
+	InputTapInternalKey:         "tap internal key",
+	InputTapMerkleRoot:          "tap merkle root",
+	InputProprietary:            "proprietary",
+}
+
+var outputNames = map[byte]string{
+	OutputRedeemScript:       "redeem script",
+	OutputWitnessScript:      "witness script",
+	OutputBIP32Derivation:    "bip32 derivation",
+	OutputAmount:             "amount",
+	OutputScript:             "script",
+	OutputTapInternalKey:     "tap internal key",
+	OutputTapTree:            "tap tree",
+	OutputTapBIP32Derivation: "tap bip32 derivation",
+	OutputProprietary:        "proprietary",
+}
+
+// GlobalTypeName returns the name of a global type, like "unsigned tx"
+func GlobalTypeName(keyType byte) string {
+	return typeName(globalNames, keyType)
+}
+
+// InputTypeName returns the name of an input type, like "partial sig"
+func InputTypeName(keyType byte) string {
+	return typeName(inputNames, keyType)
+}
+
+// OutputTypeName returns the name of an output type, like "redeem script"
+func OutputTypeName(keyType byte) string {
+	return typeName(outputNames, keyType)
+}
+
+func typeName(names map[byte]string, keyType byte) string {
+	if name, ok := names[keyType]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (%02x)", keyType)
+}
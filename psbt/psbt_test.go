@@ -0,0 +1,205 @@
+package psbt_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/psbt"
+	"github.com/ottosch/pick-private/tx"
+)
+
+// BIP143 P2SH-P2WPKH example
+const (
+	unsignedHex = "0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000"
+	signedHex   = "01000000000101db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a5477010000001716001479091972186c449eb1ded22b78e40d009bdf0089feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac02473044022047ac8e878352d3ebbde1c94ce3a10d057c24175747116f8288e5d794d12d482f0220217f36a485cae903c713331d877c1f64677e3622ad4010726870540656fe9dcb012103ad1d8e89212f0b92c74d23bb710c00662ad1470198ac48c43f7d6f93a2a2687392040000"
+	privateKey  = "eb696a065ef48a2192da5b28b694f87544b30fae8327c4510137a922f32c6dcf"
+	witnessUTXO = "00ca9a3b0000000017a9144733f37cf4db86fbc2efed2500b4f4e49f31202387"
+)
+
+func decode(s string) []byte {
+	data, _ := hex.DecodeString(s)
+	return data
+}
+
+func newKey(s string) keys.PrivateKey {
+	number, _ := new(big.Int).SetString(s, 16)
+	return keys.FromBigInt(number, chaincfg.MainNet)
+}
+
+func TestSignFinalizeExtract(t *testing.T) {
+	unsigned, _ := tx.Deserialize(decode(unsignedHex))
+	p, err := psbt.New(unsigned)
+	if err != nil {
+		t.Fatalf("New FAILED: %v\n", err)
+	}
+	p.Inputs[0].Set(psbt.InputWitnessUTXO, nil, decode(witnessUTXO))
+
+	decoded, err := psbt.Decode(p.String())
+	if err != nil || decoded.String() != p.String() {
+		t.Fatalf("Decode FAILED: %v\n", err)
+	}
+
+	if signed, err := decoded.Sign([]keys.PrivateKey{newKey(privateKey), newKey("01")}, tx.SigHashAll); err != nil || len(signed) != 1 {
+		t.Fatalf("Sign FAILED: %d signed, %v\n", len(signed), err)
+	}
+	if extracted, err := decoded.Extract(); err == nil {
+		t.Errorf("Extract before Finalize passed, should've failed: %x\n", extracted.Serialize())
+	}
+
+	if finalized, err := decoded.Finalize(); err != nil || finalized != 1 {
+		t.Fatalf("Finalize FAILED: %d finalized, %v\n", finalized, err)
+	}
+	if len(decoded.Inputs[0].GetAll(psbt.InputPartialSig)) != 0 || decoded.Inputs[0].Get(psbt.InputWitnessUTXO) == nil {
+		t.Errorf("Finalize FAILED: partial signatures kept or UTXO removed\n")
+	}
+
+	extracted, err := decoded.Extract()
+	switch {
+	case err != nil:
+		t.Errorf("Extract FAILED: %v\n", err)
+	case hex.EncodeToString(extracted.Serialize()) != signedHex:
+		t.Errorf("Extract FAILED. Expected %s, got %x\n", signedHex, extracted.Serialize())
+	default:
+		t.Logf("Extract passed: %s\n", signedHex)
+	}
+}
+
+func TestNonWitnessUTXO(t *testing.T) {
+	key := keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+	prevTx := &tx.Tx{
+		Version: 2,
+		Inputs:  []tx.Input{{PrevHash: make([]byte, 32), Sequence: 0xffffffff}},
+		Outputs: []tx.Output{{Value: 50000, Script: decode(key.ToScriptLegacy())}, {Value: 60000, Script: decode(key.ToScriptTaproot())}},
+	}
+
+	unsigned, _ := tx.Deserialize(decode(unsignedHex))
+	unsigned.Inputs[0].PrevHash, unsigned.Inputs[0].PrevIndex = prevTx.Hash(), 0
+	unsigned.Inputs = append(unsigned.Inputs, tx.Input{PrevHash: prevTx.Hash(), PrevIndex: 1})
+
+	p, _ := psbt.New(unsigned)
+	p.Inputs[0].Set(psbt.InputNonWitnessUTXO, nil, prevTx.Serialize())
+	p.Inputs[1].Set(psbt.InputNonWitnessUTXO, nil, prevTx.Serialize())
+
+	if signed, err := p.Sign([]keys.PrivateKey{key}, tx.SigHashAll); err != nil || len(signed) != 2 {
+		t.Fatalf("Sign FAILED: %d signed, %v\n", len(signed), err)
+	}
+	p.Finalize()
+	extracted, err := p.Extract()
+	if err != nil {
+		t.Fatalf("Extract FAILED: %v\n", err)
+	}
+
+	expected, _ := tx.Deserialize(decode(unsignedHex))
+	expected.Inputs = unsigned.Inputs
+	expected.Sign([]tx.Output{prevTx.Outputs[0], prevTx.Outputs[1]}, []keys.PrivateKey{key}, tx.SigHashAll)
+
+	if result := hex.EncodeToString(extracted.Serialize()); result != hex.EncodeToString(expected.Serialize()) {
+		t.Errorf("Extract FAILED. Expected %x, got %s\n", expected.Serialize(), result)
+	} else {
+		t.Logf("Extract passed: %s\n", result)
+	}
+
+	p.Inputs[0].Set(psbt.InputNonWitnessUTXO, nil, decode(unsignedHex))
+	if _, err := p.Prevout(0); err == nil {
+		t.Errorf("Prevout with a mismatched non-witness UTXO passed, should've failed: FAIL\n")
+	}
+}
+
+func TestVersion2(t *testing.T) {
+	unsigned, _ := tx.Deserialize(decode(unsignedHex))
+	in := unsigned.Inputs[0]
+
+	uint32Bytes := func(n uint32) []byte { return binary.LittleEndian.AppendUint32(nil, n) }
+	p := &psbt.PSBT{
+		Global: psbt.Map{
+			{Type: psbt.GlobalTxVersion, Value: uint32Bytes(uint32(unsigned.Version))},
+			{Type: psbt.GlobalFallbackLockTime, Value: uint32Bytes(unsigned.LockTime)},
+			{Type: psbt.GlobalInputCount, Value: []byte{1}},
+			{Type: psbt.GlobalOutputCount, Value: []byte{2}},
+			{Type: psbt.GlobalVersion, Value: uint32Bytes(2)},
+		},
+		Inputs: []psbt.Map{{
+			{Type: psbt.InputPreviousTxid, Value: in.PrevHash},
+			{Type: psbt.InputOutputIndex, Value: uint32Bytes(in.PrevIndex)},
+			{Type: psbt.InputSequence, Value: uint32Bytes(in.Sequence)},
+			{Type: psbt.InputWitnessUTXO, Value: decode(witnessUTXO)},
+		}},
+	}
+	for _, out := range unsigned.Outputs {
+		p.Outputs = append(p.Outputs, psbt.Map{
+			{Type: psbt.OutputAmount, Value: binary.LittleEndian.AppendUint64(nil, uint64(out.Value))},
+			{Type: psbt.OutputScript, Value: out.Script},
+		})
+	}
+
+	decoded, err := psbt.Decode(hex.EncodeToString(p.Serialize()))
+	if err != nil || decoded.Version() != 2 {
+		t.Fatalf("Decode FAILED: %v\n", err)
+	}
+
+	decoded.Sign([]keys.PrivateKey{newKey(privateKey)}, tx.SigHashAll)
+	decoded.Finalize()
+	extracted, err := decoded.Extract()
+	switch {
+	case err != nil:
+		t.Errorf("Extract FAILED: %v\n", err)
+	case hex.EncodeToString(extracted.Serialize()) != signedHex:
+		t.Errorf("Extract FAILED. Expected %s, got %x\n", signedHex, extracted.Serialize())
+	default:
+		t.Logf("Extract passed: %s\n", signedHex)
+	}
+
+	for _, version := range []uint32{1, 3} {
+		p.Global.Set(psbt.GlobalVersion, nil, uint32Bytes(version))
+		if _, err := psbt.Decode(hex.EncodeToString(p.Serialize())); err == nil {
+			t.Errorf("Decode of version %d passed, should've failed: FAIL\n", version)
+		}
+	}
+}
+
+func TestSighashType(t *testing.T) {
+	unsigned, _ := tx.Deserialize(decode(unsignedHex))
+	uint32Bytes := func(n uint32) []byte { return binary.LittleEndian.AppendUint32(nil, n) }
+
+	tests := []struct {
+		field   uint32
+		allowed byte
+		valid   bool
+	}{
+		{uint32(tx.SigHashAll), tx.SigHashAll, true},
+		{uint32(tx.SigHashNone), tx.SigHashAll, false},
+		{uint32(tx.SigHashAll | tx.SigHashAnyoneCanPay), tx.SigHashAll, false},
+		{uint32(tx.SigHashNone), tx.SigHashNone, true},
+		{0x101, tx.SigHashAll, false},
+	}
+
+	for _, test := range tests {
+		p, _ := psbt.New(unsigned)
+		p.Inputs[0].Set(psbt.InputWitnessUTXO, nil, decode(witnessUTXO))
+		p.Inputs[0].Set(psbt.InputSighashType, nil, uint32Bytes(test.field))
+
+		signed, err := p.Sign([]keys.PrivateKey{newKey(privateKey)}, test.allowed)
+		switch {
+		case (err == nil) != test.valid:
+			t.Errorf("Sign with sighash %x, allowed %x FAILED. Expected valid %t, got %v\n", test.field, test.allowed, test.valid, err)
+		case err == nil && (len(signed) != 1 || uint32(signed[0].HashType) != test.field):
+			t.Errorf("Sign with sighash %x FAILED. Got %v\n", test.field, signed)
+		default:
+			t.Logf("Sign with sighash %x, allowed %x passed: %v\n", test.field, test.allowed, err)
+		}
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	for _, test := range []string{"", "cHNidP8=", "70736274ff0000", "0102"} {
+		if _, err := psbt.Decode(test); err == nil {
+			t.Errorf("Decode for %q passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("Decode for %q failed: %v\n", test, err)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/tx"
+)
+
+// Signed is an input signature added by Sign, with its sighash type
+type Signed struct {
+	Input    int
+	HashType byte
+}
+
+// Sign adds partial signatures (or the taproot key-path signature) to every input whose
+// P2PKH, P2SH-P2WPKH, P2WPKH or P2TR script belongs to one of the keys, returning the
+// signatures added. Finalized inputs are skipped. Inputs are signed with the allowed
+// sighash type: an input asking for another PSBT_IN_SIGHASH_TYPE is an error.
+func (p *PSBT) Sign(privKeys []keys.PrivateKey, allowed byte) ([]Signed, error) {
+	unsigned, err := p.UnsignedTx()
+	if err != nil {
+		return nil, err
+	}
+
+	var signed []Signed
+	for i := range p.Inputs {
+		if p.isFinalized(i) {
+			continue
+		}
+
+		for k := range privKeys {
+			hashType, ok, err := p.signInput(unsigned, i, &privKeys[k], allowed)
+			if err != nil {
+				return signed, fmt.Errorf("input %d: %v", i, err)
+			}
+			if ok {
+				signed = append(signed, Signed{i, hashType})
+			}
+		}
+	}
+	return signed, nil
+}
+
+// sighashType returns the input's PSBT_IN_SIGHASH_TYPE, which must be the allowed type,
+// or the allowed type if there is none. Taproot signs SIGHASH_ALL as SIGHASH_DEFAULT.
+func sighashType(input Map, allowed byte, taproot bool) (byte, error) {
+	value := input.Get(InputSighashType)
+	if value == nil {
+		if taproot && allowed == tx.SigHashAll {
+			return tx.SigHashDefault, nil
+		}
+		return allowed, nil
+	}
+
+	if len(value) != 4 {
+		return 0, errors.New("invalid sighash type field")
+	}
+	field := binary.LittleEndian.Uint32(value)
+	if field > 0xff {
+		return 0, fmt.Errorf("invalid sighash type: %08x", field)
+	}
+
+	hashType := byte(field)
+	if hashType != allowed && !(taproot && hashType == tx.SigHashDefault && allowed == tx.SigHashAll) {
+		return 0, fmt.Errorf("sighash type %02x does not match the allowed type %02x", hashType, allowed)
+	}
+	return hashType, nil
+}
+
+func (p *PSBT) signInput(unsigned *tx.Tx, index int, priv *keys.PrivateKey, allowed byte) (byte, bool, error) {
+	input := &p.Inputs[index]
+	prevout, err := p.Prevout(index)
+	if err != nil {
+		return 0, false, err
+	}
+
+	scriptHex := hex.EncodeToString(prevout.Script)
+	switch scriptHex {
+	case priv.ToScriptLegacy(), priv.ToScriptLegacyUncompressed(), priv.ToScriptSegwitCompat(), priv.ToScriptSegwit():
+	case priv.ToScriptTaproot():
+		if input.Get(InputTapMerkleRoot) != nil {
+			return 0, false, nil
+		}
+	default:
+		return 0, false, nil
+	}
+
+	hashType, err := sighashType(*input, allowed, scriptHex == priv.ToScriptTaproot())
+	if err != nil {
+		return 0, false, err
+	}
+
+	var sighash, pubkey []byte
+	scriptCode, _ := hex.DecodeString(priv.ToScriptLegacy())
+
+	switch scriptHex {
+	case priv.ToScriptLegacy():
+		pubkey = priv.PublicKey()
+		sighash, err = unsigned.SigHashLegacy(index, prevout.Script, hashType)
+
+	case priv.ToScriptLegacyUncompressed():
+		pubkey = priv.PublicKeyUncompressed()
+		sighash, err = unsigned.SigHashLegacy(index, prevout.Script, hashType)
+
+	case priv.ToScriptSegwitCompat():
+		redeem := script.WitnessProgram(0, priv.ToPublicKeyHash())
+		if current := input.Get(InputRedeemScript); current != nil && !bytes.Equal(current, redeem) {
+			return 0, false, errors.New("redeem script does not match the key")
+		}
+		input.Set(InputRedeemScript, nil, redeem)
+
+		pubkey = priv.PublicKey()
+		sighash, err = unsigned.SigHashSegwitV0(index, scriptCode, prevout.Value, hashType)
+
+	case priv.ToScriptSegwit():
+		pubkey = priv.PublicKey()
+		sighash, err = unsigned.SigHashSegwitV0(index, scriptCode, prevout.Value, hashType)
+
+	case priv.ToScriptTaproot():
+		prevouts, err := p.Prevouts()
+		if err != nil {
+			return 0, false, err
+		}
+		if sighash, err = unsigned.SigHashTaproot(index, prevouts, hashType); err != nil {
+			return 0, false, err
+		}
+
+		signature, err := priv.SignTaproot(sighash, nil)
+		if err != nil {
+			return 0, false, err
+		}
+		if hashType != tx.SigHashDefault {
+			signature = append(signature, hashType)
+		}
+		input.Set(InputTapKeySig, nil, signature)
+		input.Set(InputTapInternalKey, nil, priv.TaprootInternalKey())
+		return hashType, true, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	signature, err := priv.SignECDSA(sighash)
+	if err != nil {
+		return 0, false, err
+	}
+	input.Set(InputPartialSig, pubkey, append(signature, hashType))
+	return hashType, true, nil
+}
+
+// Finalize builds the final scriptSig and witness of every signed single-key input
+// (P2PKH, P2SH-P2WPKH, P2WPKH and P2TR key path), returning how many inputs are finalized.
+func (p *PSBT) Finalize() (int, error) {
+	finalized := 0
+	for i := range p.Inputs {
+		if !p.isFinalized(i) {
+			if err := p.finalizeInput(i); err != nil {
+				return finalized, fmt.Errorf("input %d: %v", i, err)
+			}
+		}
+		if p.isFinalized(i) {
+			finalized++
+		}
+	}
+	return finalized, nil
+}
+
+func (p *PSBT) finalizeInput(index int) error {
+	input := &p.Inputs[index]
+	prevout, err := p.Prevout(index)
+	if err != nil {
+		return err
+	}
+
+	class, data := script.Classify(prevout.Script)
+	switch class {
+	case script.ClassPubKeyHash:
+		sig, pubkey := partialSig(*input, data[0])
+		if sig == nil {
+			return nil
+		}
+		input.Set(InputFinalScriptSig, nil, append(script.PushData(sig), script.PushData(pubkey)...))
+
+	case script.ClassScriptHash:
+		redeem := input.Get(InputRedeemScript)
+		redeemClass, program := script.Classify(redeem)
+		if redeemClass != script.ClassWitnessV0KeyHash || !bytes.Equal(crypto.Hash160(redeem), data[0]) {
+			return nil
+		}
+		sig, pubkey := partialSig(*input, program[0])
+		if sig == nil {
+			return nil
+		}
+		input.Set(InputFinalScriptSig, nil, script.PushData(redeem))
+		input.Set(InputFinalScriptWitness, nil, tx.SerializeWitness([][]byte{sig, pubkey}))
+
+	case script.ClassWitnessV0KeyHash:
+		sig, pubkey := partialSig(*input, data[0])
+		if sig == nil {
+			return nil
+		}
+		input.Set(InputFinalScriptWitness, nil, tx.SerializeWitness([][]byte{sig, pubkey}))
+
+	case script.ClassWitnessV1Taproot:
+		sig := input.Get(InputTapKeySig)
+		if sig == nil {
+			return nil
+		}
+		input.Set(InputFinalScriptWitness, nil, tx.SerializeWitness([][]byte{sig}))
+
+	default:
+		return nil
+	}
+
+	// BIP174: the finalizer removes everything but the UTXOs, final fields and unknowns
+	for _, keyType := range []byte{InputPartialSig, InputSighashType, InputRedeemScript, InputWitnessScript,
+		InputBIP32Derivation, InputRipemd160, InputSha256, InputHash160, InputHash256, InputTapKeySig,
+		InputTapScriptSig, InputTapLeafScript, InputTapBIP32Derivation, InputTapInternalKey, InputTapMerkleRoot} {
+		input.Delete(keyType)
+	}
+	return nil
+}
+
+// Extract returns the signed network transaction of a fully finalized PSBT
+func (p *PSBT) Extract() (*tx.Tx, error) {
+	signed, err := p.UnsignedTx()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range p.Inputs {
+		if !p.isFinalized(i) {
+			return nil, fmt.Errorf("input %d is not finalized", i)
+		}
+
+		signed.Inputs[i].ScriptSig = p.Inputs[i].Get(InputFinalScriptSig)
+		if witness := p.Inputs[i].Get(InputFinalScriptWitness); witness != nil {
+			if signed.Inputs[i].Witness, err = tx.DeserializeWitness(witness); err != nil {
+				return nil, fmt.Errorf("input %d: %v", i, err)
+			}
+		}
+	}
+	return signed, nil
+}
+
+func (p *PSBT) isFinalized(index int) bool {
+	return p.Inputs[index].Get(InputFinalScriptSig) != nil || p.Inputs[index].Get(InputFinalScriptWitness) != nil
+}
+
+// partialSig returns the partial signature of the public key with the given hash
+func partialSig(input Map, pubkeyHash []byte) ([]byte, []byte) {
+	for _, pair := range input.GetAll(InputPartialSig) {
+		if bytes.Equal(crypto.Hash160(pair.KeyData), pubkeyHash) {
+			return pair.Value, pair.KeyData
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,107 @@
+package schnorr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// Sign returns the 64-byte BIP340 signature of a message with a 32-byte private key.
+// auxRand is the 32-byte auxiliary randomness; nil uses zeros, as in deterministic signing.
+func Sign(privateKey, message, auxRand []byte) ([]byte, error) {
+	curve := secp256k1.S256()
+	n := curve.Params().N
+
+	if auxRand == nil {
+		auxRand = make([]byte, 32)
+	}
+	if len(auxRand) != 32 {
+		return nil, fmt.Errorf("invalid auxiliary randomness length: %d", len(auxRand))
+	}
+
+	d := new(big.Int).SetBytes(privateKey)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, errors.New("invalid private key: out of range")
+	}
+
+	px, py := curve.ScalarBaseMult(scalar(d))
+	if py.Bit(0) == 1 {
+		d.Sub(n, d)
+	}
+	pubkey := scalar(px)
+
+	t := new(big.Int).SetBytes(crypto.TaggedHash("BIP0340/aux", auxRand))
+	t.Xor(t, d)
+
+	k := new(big.Int).SetBytes(crypto.TaggedHash("BIP0340/nonce", scalar(t), pubkey, message))
+	k.Mod(k, n)
+	if k.Sign() == 0 {
+		return nil, errors.New("invalid nonce, use other auxiliary randomness")
+	}
+
+	rx, ry := curve.ScalarBaseMult(scalar(k))
+	if ry.Bit(0) == 1 {
+		k.Sub(n, k)
+	}
+	r := scalar(rx)
+
+	e := challenge(r, pubkey, message)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, n)
+
+	signature := append(r, scalar(s)...)
+	if err := Verify(pubkey, message, signature); err != nil {
+		return nil, fmt.Errorf("created an invalid signature: %v", err)
+	}
+	return signature, nil
+}
+
+// Verify checks a 64-byte BIP340 signature of a message against an x-only public key
+func Verify(pubkey, message, signature []byte) error {
+	curve := secp256k1.S256()
+	n := curve.Params().N
+
+	if len(pubkey) != 32 {
+		return fmt.Errorf("invalid x-only public key length: %d", len(pubkey))
+	}
+	if len(signature) != 64 {
+		return fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+
+	point, err := secp256k1.ParsePubKey(append([]byte{0x02}, pubkey...))
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Cmp(curve.Params().P) >= 0 || s.Cmp(n) >= 0 {
+		return errors.New("invalid signature: out of range")
+	}
+
+	e := challenge(signature[:32], pubkey, message)
+	e.Sub(n, e)
+
+	sx, sy := curve.ScalarBaseMult(scalar(s))
+	ex, ey := curve.ScalarMult(point.X, point.Y, scalar(e))
+	rx, ry := curve.Add(sx, sy, ex, ey)
+
+	if rx.Sign() == 0 && ry.Sign() == 0 || ry.Bit(0) == 1 || !bytes.Equal(scalar(rx), signature[:32]) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func challenge(r, pubkey, message []byte) *big.Int {
+	e := new(big.Int).SetBytes(crypto.TaggedHash("BIP0340/challenge", r, pubkey, message))
+	return e.Mod(e, secp256k1.S256().Params().N)
+}
+
+func scalar(number *big.Int) []byte {
+	return number.FillBytes(make([]byte, 32))
+}
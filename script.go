@@ -0,0 +1,219 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/script"
+)
+
+func runScript(args []string) {
+	flags := flag.NewFlagSet("script", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s script [options] decode hex | assemble asm | eval scriptPubKey\n", os.Args[0])
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s script decode 76a914751e76e8199196d454941c45d1b3a323f1433bd688ac\n", os.Args[0])
+		fmt.Printf("  %s script assemble OP_DUP OP_HASH160 751e76e8199196d454941c45d1b3a323f1433bd6 OP_EQUALVERIFY OP_CHECKSIG\n", os.Args[0])
+		fmt.Printf("  %s script -scriptsig \"2 3\" eval OP_ADD 5 OP_EQUAL\n", os.Args[0])
+	}
+
+	var coinFlag, networkFlag string
+	flags.StringVar(&coinFlag, "coin", "btc", coinUsage)
+	flags.StringVar(&networkFlag, "network", "mainnet", networkUsage)
+
+	var witnessFlags stringList
+	var scriptSigFlag, sigHashFlag string
+	var ctx script.Context
+	flags.StringVar(&scriptSigFlag, "scriptsig", "", "eval: scriptSig, as hex or ASM")
+	flags.Var(&witnessFlags, "witness", "eval: witness item, as hex. Can be repeated, in order; the witness script goes last")
+	flags.StringVar(&sigHashFlag, "sighash", "", "eval: sighash digest (hex) signatures are checked against")
+	flags.Func("locktime", "eval: transaction locktime, for OP_CHECKLOCKTIMEVERIFY (default 0)", func(s string) error {
+		n, err := strconv.ParseUint(s, 10, 32)
+		ctx.LockTime = uint32(n)
+		return err
+	})
+	ctx.Sequence = 0xfffffffe
+	flags.Func("sequence", "eval: input sequence, for OP_CHECKLOCKTIMEVERIFY and OP_CHECKSEQUENCEVERIFY (default 4294967294)", func(s string) error {
+		n, err := strconv.ParseUint(s, 10, 32)
+		ctx.Sequence = uint32(n)
+		return err
+	})
+	ctx.Version = 2
+	flags.Func("version", "eval: transaction version, for OP_CHECKSEQUENCEVERIFY (default 2)", func(s string) error {
+		n, err := strconv.ParseInt(s, 10, 32)
+		ctx.Version = int32(n)
+		return err
+	})
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) < 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	params := lookupNetwork(coinFlag, networkFlag)
+
+	var data []byte
+	var err error
+	switch positional[0] {
+	case "decode":
+		data, err = hex.DecodeString(strings.Join(positional[1:], ""))
+	case "assemble":
+		data, err = script.Assemble(strings.Join(positional[1:], " "))
+	case "eval":
+		evalScript(strings.Join(positional[1:], " "), scriptSigFlag, witnessFlags, sigHashFlag, &ctx)
+		return
+	default:
+		flags.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	asm, err := script.Disassemble(data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	class, pushes := script.Classify(data)
+	fmt.Println("[Script]")
+	fmt.Printf("    Hex: %x\n", data)
+	fmt.Printf("    ASM: %s\n", asm)
+	fmt.Printf("   Type: %s\n", class)
+	if addr, err := address.FromScript(data, params); err == nil {
+		fmt.Printf("Address: %s\n", addr)
+	}
+	if class == script.ClassMultisig {
+		fmt.Printf("   Keys: %d of %d\n", data[0]-script.Op1+1, len(pushes))
+		for i, pubkey := range pushes {
+			fmt.Printf("  Key %d: %x\n", i+1, pubkey)
+		}
+	}
+
+	// as a redeem or witness script, unless it is already an output template
+	switch class {
+	case script.ClassScriptHash, script.ClassWitnessV0KeyHash, script.ClassWitnessV0Script,
+		script.ClassWitnessV1Taproot, script.ClassWitnessUnknown, script.ClassNullData:
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("[As redeem script]")
+	p2sh := script.P2SH(crypto.Hash160(data))
+	p2shAddress, _ := address.FromScript(p2sh, params)
+	fmt.Printf("   P2SH: %s\n", p2shAddress)
+
+	if params.SegWit {
+		scriptHash := sha256.Sum256(data)
+		p2wsh := script.WitnessProgram(0, scriptHash[:])
+		p2wshAddress, _ := address.FromScript(p2wsh, params)
+		fmt.Printf("  P2WSH: %s\n", p2wshAddress)
+	}
+}
+
+// evalScript runs a scriptSig and witness against a scriptPubKey, printing every step
+func evalScript(scriptPubKeyArg, scriptSigArg string, witnessArgs []string, sigHashArg string, ctx *script.Context) {
+	scriptPubKey, err := parseScriptArg(scriptPubKeyArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	scriptSig, err := parseScriptArg(scriptSigArg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var witness [][]byte
+	for _, item := range witnessArgs {
+		data, err := hex.DecodeString(item)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid witness item %q: %v\n", item, err)
+			os.Exit(1)
+		}
+		witness = append(witness, data)
+	}
+
+	if sigHashArg != "" {
+		if ctx.SigHash, err = hex.DecodeString(sigHashArg); err != nil || len(ctx.SigHash) != 32 {
+			fmt.Fprintf(os.Stderr, "invalid sighash digest: %s\n", sigHashArg)
+			os.Exit(1)
+		}
+	}
+
+	current := ""
+	err = script.Verify(scriptSig, witness, scriptPubKey, ctx, func(step script.Step) {
+		if step.Script != current {
+			if current != "" {
+				fmt.Println()
+			}
+			current = step.Script
+			fmt.Printf("[%s]\n", step.Script)
+		}
+
+		if !step.Executed {
+			fmt.Printf("%4d: %-24s (not executed)\n", step.Index, step.Instruction)
+			return
+		}
+		fmt.Printf("%4d: %-24s Stack: %s\n", step.Index, step.Instruction, formatStack(step.Stack))
+		if len(step.AltStack) > 0 {
+			fmt.Printf("      %-24s   Alt: %s\n", "", formatStack(step.AltStack))
+		}
+	})
+
+	if current != "" {
+		fmt.Println()
+	}
+	if err != nil {
+		fmt.Printf("Result: FAIL (%v)\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Result: OK")
+}
+
+// parseScriptArg reads a script given as hex or, failing that, as ASM
+func parseScriptArg(s string) ([]byte, error) {
+	if data, err := hex.DecodeString(s); err == nil {
+		return data, nil
+	}
+	return script.Assemble(s)
+}
+
+// formatStack returns the stack items in hex, bottom first, with empty items as []
+func formatStack(stack [][]byte) string {
+	if len(stack) == 0 {
+		return "(empty)"
+	}
+	items := make([]string, len(stack))
+	for i, item := range stack {
+		if len(item) == 0 {
+			items[i] = "[]"
+		} else {
+			items[i] = hex.EncodeToString(item)
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+// scriptAsm returns the ASM of a hex script
+func scriptAsm(hexScript string) string {
+	data, _ := hex.DecodeString(hexScript)
+	asm, err := script.Disassemble(data)
+	if err != nil {
+		return err.Error()
+	}
+	return asm
+}
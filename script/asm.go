@@ -0,0 +1,106 @@
+package script
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	regexNumber   = regexp.MustCompile(`^(0|-?[1-9][0-9]{0,9})$`)
+	opcodesByName map[string]byte
+)
+
+func init() {
+	opcodesByName = make(map[string]byte, len(opcodeNames)+len(opcodeAliases))
+	for opcode, name := range opcodeNames {
+		opcodesByName[name] = opcode
+	}
+	for name, opcode := range opcodeAliases {
+		opcodesByName[name] = opcode
+	}
+}
+
+// Assemble converts ASM to a script. Tokens are opcode names (the OP_ prefix is optional),
+// decimal numbers pushed as script numbers, and hex data pushed with the smallest push.
+// A 0x prefix forces a token to be read as hex data.
+func Assemble(asm string) ([]byte, error) {
+	var script []byte
+	for _, token := range strings.Fields(asm) {
+		upper := strings.ToUpper(token)
+		if !strings.HasPrefix(upper, "OP_") {
+			upper = "OP_" + upper
+		}
+
+		if opcode, ok := opcodesByName[upper]; ok && !isNumberToken(token) {
+			script = append(script, opcode)
+			continue
+		}
+
+		if isNumberToken(token) {
+			n, _ := strconv.ParseInt(token, 10, 64)
+			script = append(script, PushInt(n)...)
+			continue
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(token, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ASM token: %s", token)
+		}
+		script = append(script, PushData(data)...)
+	}
+	return script, nil
+}
+
+// isNumberToken reports whether a token is a decimal number, without leading zeros,
+// in the 4-byte script number range
+func isNumberToken(token string) bool {
+	if !regexNumber.MatchString(token) {
+		return false
+	}
+	n, err := strconv.ParseInt(token, 10, 64)
+	return err == nil && n >= -math.MaxInt32 && n <= math.MaxInt32
+}
+
+// Disassemble converts a script to ASM, as Bitcoin Core does: pushes of up to 4 bytes
+// are shown as script numbers, larger pushes as hex
+func Disassemble(script []byte) (string, error) {
+	instructions, err := Parse(script)
+	if err != nil {
+		return "", err
+	}
+
+	tokens := make([]string, len(instructions))
+	for i, ins := range instructions {
+		tokens[i] = ins.String()
+	}
+	return strings.Join(tokens, " "), nil
+}
+
+// String returns the ASM of the instruction
+func (ins Instruction) String() string {
+	switch {
+	case ins.Opcode == Op0:
+		return "0"
+	case ins.Opcode == Op1Negate:
+		return "-1"
+	case ins.Opcode >= Op1 && ins.Opcode <= Op16:
+		return strconv.Itoa(int(ins.Opcode - Op1 + 1))
+	case ins.Opcode > Op0 && ins.Opcode <= OpPushData4:
+		if len(ins.Data) <= 4 {
+			if n, err := DecodeNumber(ins.Data, 4); err == nil {
+				return strconv.FormatInt(n, 10)
+			}
+		}
+		if data := hex.EncodeToString(ins.Data); !isNumberToken(data) {
+			return data
+		}
+		// hex that reads as a number is prefixed, so that it assembles back to the same push
+		return "0x" + hex.EncodeToString(ins.Data)
+	default:
+		return OpcodeName(ins.Opcode)
+	}
+}
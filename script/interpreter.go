@@ -0,0 +1,794 @@
+package script
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// Interpreter limits, as in Bitcoin Core
+const (
+	maxScriptSize    = 10000
+	maxElementSize   = 520
+	maxStackSize     = 1000
+	maxOpsPerScript  = 201
+	maxPubkeysPerSig = 20
+
+	lockTimeThreshold = 500000000
+
+	sequenceFinal       = 0xffffffff
+	sequenceDisableFlag = 1 << 31
+	sequenceTypeFlag    = 1 << 22
+	sequenceMask        = 0x0000ffff
+)
+
+// Context is the spending transaction data the interpreter checks against. As there is no
+// transaction, CHECKSIG verifies signatures against the given sighash digest.
+type Context struct {
+	SigHash  []byte
+	LockTime uint32
+	Sequence uint32
+	Version  int32
+}
+
+// Step is the state after an instruction, for tracing
+type Step struct {
+	Script      string
+	Index       int
+	Instruction Instruction
+	Executed    bool
+	Stack       [][]byte
+	AltStack    [][]byte
+}
+
+// Verify runs a scriptSig and witness against a scriptPubKey: legacy, P2SH, P2WPKH, P2WSH
+// and P2SH-wrapped segwit spends. Witness v1 (taproot) and other witness versions are not
+// evaluated and return an error. trace, if not nil, is called after every instruction.
+func Verify(scriptSig []byte, witness [][]byte, scriptPubKey []byte, ctx *Context, trace func(Step)) error {
+	if !isPushOnly(scriptSig) && len(scriptSig) > 0 {
+		if class, _ := Classify(scriptPubKey); class == ClassScriptHash {
+			return errors.New("P2SH scriptSig must be push only")
+		}
+	}
+
+	stack, err := execute("scriptSig", scriptSig, nil, ctx, trace)
+	if err != nil {
+		return err
+	}
+	p2shStack := append([][]byte{}, stack...)
+
+	if stack, err = execute("scriptPubKey", scriptPubKey, stack, ctx, trace); err != nil {
+		return err
+	}
+	if err := checkResult(stack); err != nil {
+		return err
+	}
+
+	if version, program, ok := witnessProgram(scriptPubKey); ok {
+		if len(scriptSig) > 0 {
+			return errors.New("native segwit spend with a non-empty scriptSig")
+		}
+		return verifyWitness(version, program, witness, ctx, trace)
+	}
+
+	if class, _ := Classify(scriptPubKey); class == ClassScriptHash {
+		redeem := p2shStack[len(p2shStack)-1]
+		if stack, err = execute("redeemScript", redeem, p2shStack[:len(p2shStack)-1], ctx, trace); err != nil {
+			return err
+		}
+		if err := checkResult(stack); err != nil {
+			return err
+		}
+
+		if version, program, ok := witnessProgram(redeem); ok {
+			if !bytes.Equal(scriptSig, PushData(redeem)) {
+				return errors.New("P2SH-wrapped segwit scriptSig must only push the redeem script")
+			}
+			return verifyWitness(version, program, witness, ctx, trace)
+		}
+	}
+
+	if len(witness) > 0 {
+		return errors.New("witness given for a non-segwit spend")
+	}
+	return nil
+}
+
+func verifyWitness(version int, program []byte, witness [][]byte, ctx *Context, trace func(Step)) error {
+	if version != 0 {
+		return fmt.Errorf("witness version %d is not supported", version)
+	}
+
+	var witnessScript []byte
+	var stack [][]byte
+	switch len(program) {
+	case 20:
+		if len(witness) != 2 {
+			return fmt.Errorf("P2WPKH witness must have 2 items, got %d", len(witness))
+		}
+		witnessScript, stack = P2PKH(program), witness
+	case 32:
+		if len(witness) == 0 {
+			return errors.New("empty P2WSH witness")
+		}
+		witnessScript, stack = witness[len(witness)-1], witness[:len(witness)-1]
+		if hash := sha256.Sum256(witnessScript); !bytes.Equal(hash[:], program) {
+			return errors.New("witness script does not match the P2WSH program")
+		}
+	default:
+		return fmt.Errorf("invalid witness program length: %d", len(program))
+	}
+
+	for _, item := range stack {
+		if len(item) > maxElementSize {
+			return fmt.Errorf("witness item of %d bytes, more than %d", len(item), maxElementSize)
+		}
+	}
+
+	stack, err := execute("witnessScript", witnessScript, append([][]byte{}, stack...), ctx, trace)
+	if err != nil {
+		return err
+	}
+	if len(stack) != 1 {
+		return fmt.Errorf("witness script left %d stack items, expected 1", len(stack))
+	}
+	return checkResult(stack)
+}
+
+func checkResult(stack [][]byte) error {
+	if len(stack) == 0 || !castToBool(stack[len(stack)-1]) {
+		return errors.New("script evaluated to false")
+	}
+	return nil
+}
+
+func isPushOnly(script []byte) bool {
+	instructions, err := Parse(script)
+	if err != nil {
+		return false
+	}
+	for _, ins := range instructions {
+		if !ins.IsPush() {
+			return false
+		}
+	}
+	return true
+}
+
+// machine is the state of a running script
+type machine struct {
+	stack     [][]byte
+	altStack  [][]byte
+	condition []bool
+	ops       int
+	ctx       *Context
+}
+
+func execute(name string, script []byte, stack [][]byte, ctx *Context, trace func(Step)) ([][]byte, error) {
+	if len(script) > maxScriptSize {
+		return nil, fmt.Errorf("%s: script of %d bytes, more than %d", name, len(script), maxScriptSize)
+	}
+	instructions, err := Parse(script)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	m := &machine{stack: stack, ctx: ctx}
+	for i, ins := range instructions {
+		executing := m.executing()
+
+		if ins.Opcode > Op16 {
+			if err := m.countOps(1); err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+		}
+
+		if err := m.step(ins, executing); err != nil {
+			return nil, fmt.Errorf("%s: %s (instruction %d): %v", name, ins, i, err)
+		}
+		if len(m.stack)+len(m.altStack) > maxStackSize {
+			return nil, fmt.Errorf("%s: stack size over %d", name, maxStackSize)
+		}
+
+		if trace != nil {
+			// flow control is processed in unexecuted branches too
+			executed := executing || ins.Opcode >= OpIf && ins.Opcode <= OpEndIf
+			trace(Step{name, i, ins, executed, copyStack(m.stack), copyStack(m.altStack)})
+		}
+	}
+
+	if len(m.condition) > 0 {
+		return nil, fmt.Errorf("%s: unbalanced conditional", name)
+	}
+	return m.stack, nil
+}
+
+// countOps adds n to the opcode count of the script, failing over maxOpsPerScript
+func (m *machine) countOps(n int) error {
+	if m.ops += n; m.ops > maxOpsPerScript {
+		return fmt.Errorf("more than %d opcodes", maxOpsPerScript)
+	}
+	return nil
+}
+
+func (m *machine) executing() bool {
+	for _, c := range m.condition {
+		if !c {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *machine) step(ins Instruction, executing bool) error {
+	op := ins.Opcode
+
+	if len(ins.Data) > maxElementSize {
+		return fmt.Errorf("push of %d bytes, more than %d", len(ins.Data), maxElementSize)
+	}
+
+	switch op {
+	case OpCat, OpSubstr, OpLeft, OpRight, OpInvert, OpAnd, OpOr, OpXor,
+		Op2Mul, Op2Div, OpMul, OpDiv, OpMod, OpLShift, OpRShift:
+		return errors.New("disabled opcode")
+	case OpVerIf, OpVerNotIf:
+		return errors.New("invalid opcode")
+	}
+
+	if !executing {
+		switch op {
+		case OpIf, OpNotIf:
+			m.condition = append(m.condition, false)
+		case OpElse, OpEndIf:
+			return m.flowControl(op)
+		}
+		return nil
+	}
+
+	switch {
+	case op == Op0:
+		m.push(nil)
+		return nil
+	case op <= OpPushData4:
+		m.push(ins.Data)
+		return nil
+	case op == Op1Negate || op >= Op1 && op <= Op16:
+		m.pushInt(int64(op) - int64(Op1-1))
+		return nil
+	}
+
+	switch op {
+	case OpNop, OpNop1, OpNop4, OpNop5, OpNop6, OpNop7, OpNop8, OpNop9, OpNop10, OpCodeSeparator:
+		return nil
+	case OpIf, OpNotIf, OpElse, OpEndIf:
+		return m.flowControl(op)
+	case OpVerify:
+		return m.verify()
+	case OpReturn:
+		return errors.New("OP_RETURN")
+	case OpCheckLockTimeVerify:
+		return m.checkLockTime()
+	case OpCheckSequenceVerify:
+		return m.checkSequence()
+	case OpRipemd160, OpSha1, OpSha256, OpHash160, OpHash256:
+		return m.hash(op)
+	case OpCheckSig, OpCheckSigVerify:
+		if err := m.checkSig(); err != nil {
+			return err
+		}
+		if op == OpCheckSigVerify {
+			return m.verify()
+		}
+		return nil
+	case OpCheckMultisig, OpCheckMultisigVerify:
+		if err := m.checkMultisig(); err != nil {
+			return err
+		}
+		if op == OpCheckMultisigVerify {
+			return m.verify()
+		}
+		return nil
+	}
+
+	if err := m.stackOp(op); err != errUnknownOpcode {
+		return err
+	}
+	return m.numericOp(op)
+}
+
+var errUnknownOpcode = errors.New("unknown or unsupported opcode")
+
+func (m *machine) flowControl(op byte) error {
+	switch op {
+	case OpIf, OpNotIf:
+		value, err := m.pop()
+		if err != nil {
+			return err
+		}
+		condition := castToBool(value)
+		if op == OpNotIf {
+			condition = !condition
+		}
+		m.condition = append(m.condition, condition)
+	case OpElse:
+		if len(m.condition) == 0 {
+			return errors.New("OP_ELSE without OP_IF")
+		}
+		m.condition[len(m.condition)-1] = !m.condition[len(m.condition)-1]
+	case OpEndIf:
+		if len(m.condition) == 0 {
+			return errors.New("OP_ENDIF without OP_IF")
+		}
+		m.condition = m.condition[:len(m.condition)-1]
+	}
+	return nil
+}
+
+func (m *machine) stackOp(op byte) error {
+	switch op {
+	case OpToAltStack:
+		value, err := m.pop()
+		if err != nil {
+			return err
+		}
+		m.altStack = append(m.altStack, value)
+	case OpFromAltStack:
+		if len(m.altStack) == 0 {
+			return errors.New("empty alt stack")
+		}
+		m.push(m.altStack[len(m.altStack)-1])
+		m.altStack = m.altStack[:len(m.altStack)-1]
+	case Op2Drop:
+		return m.drop(2)
+	case Op2Dup:
+		return m.copyItems(2, 2)
+	case Op3Dup:
+		return m.copyItems(3, 3)
+	case Op2Over:
+		return m.copyItems(4, 2)
+	case Op2Rot:
+		return m.move(6, 2)
+	case Op2Swap:
+		return m.move(4, 2)
+	case OpIfDup:
+		value, err := m.peek(0)
+		if err != nil {
+			return err
+		}
+		if castToBool(value) {
+			m.push(value)
+		}
+	case OpDepth:
+		m.pushInt(int64(len(m.stack)))
+	case OpDrop:
+		return m.drop(1)
+	case OpDup:
+		return m.copyItems(1, 1)
+	case OpNip:
+		if len(m.stack) < 2 {
+			return errors.New("stack too small")
+		}
+		m.stack = append(m.stack[:len(m.stack)-2], m.stack[len(m.stack)-1])
+	case OpOver:
+		return m.copyItems(2, 1)
+	case OpPick, OpRoll:
+		n, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		if n < 0 || int(n) >= len(m.stack) {
+			return fmt.Errorf("index %d out of the stack", n)
+		}
+		if op == OpPick {
+			return m.copyItems(int(n)+1, 1)
+		}
+		return m.move(int(n)+1, 1)
+	case OpRot:
+		return m.move(3, 1)
+	case OpSwap:
+		return m.move(2, 1)
+	case OpTuck:
+		if len(m.stack) < 2 {
+			return errors.New("stack too small")
+		}
+		top := m.stack[len(m.stack)-1]
+		m.stack = append(m.stack[:len(m.stack)-2], top, m.stack[len(m.stack)-2], top)
+	case OpSize:
+		value, err := m.peek(0)
+		if err != nil {
+			return err
+		}
+		m.pushInt(int64(len(value)))
+	case OpEqual, OpEqualVerify:
+		a, b, err := m.pop2()
+		if err != nil {
+			return err
+		}
+		m.pushBool(bytes.Equal(a, b))
+		if op == OpEqualVerify {
+			return m.verify()
+		}
+	default:
+		return errUnknownOpcode
+	}
+	return nil
+}
+
+func (m *machine) numericOp(op byte) error {
+	switch op {
+	case Op1Add, Op1Sub, OpNegate, OpAbs, OpNot, Op0NotEqual:
+		n, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case Op1Add:
+			n++
+		case Op1Sub:
+			n--
+		case OpNegate:
+			n = -n
+		case OpAbs:
+			if n < 0 {
+				n = -n
+			}
+		case OpNot:
+			m.pushBool(n == 0)
+			return nil
+		case Op0NotEqual:
+			m.pushBool(n != 0)
+			return nil
+		}
+		m.pushInt(n)
+
+	case OpAdd, OpSub, OpBoolAnd, OpBoolOr, OpNumEqual, OpNumEqualVerify, OpNumNotEqual,
+		OpLessThan, OpGreaterThan, OpLessThanOrEqual, OpGreaterThanOrEqual, OpMin, OpMax:
+		b, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		a, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case OpAdd:
+			m.pushInt(a + b)
+		case OpSub:
+			m.pushInt(a - b)
+		case OpBoolAnd:
+			m.pushBool(a != 0 && b != 0)
+		case OpBoolOr:
+			m.pushBool(a != 0 || b != 0)
+		case OpNumEqual, OpNumEqualVerify:
+			m.pushBool(a == b)
+			if op == OpNumEqualVerify {
+				return m.verify()
+			}
+		case OpNumNotEqual:
+			m.pushBool(a != b)
+		case OpLessThan:
+			m.pushBool(a < b)
+		case OpGreaterThan:
+			m.pushBool(a > b)
+		case OpLessThanOrEqual:
+			m.pushBool(a <= b)
+		case OpGreaterThanOrEqual:
+			m.pushBool(a >= b)
+		case OpMin:
+			if b < a {
+				a = b
+			}
+			m.pushInt(a)
+		case OpMax:
+			if b > a {
+				a = b
+			}
+			m.pushInt(a)
+		}
+
+	case OpWithin:
+		upper, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		lower, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		n, err := m.popInt(4)
+		if err != nil {
+			return err
+		}
+		m.pushBool(lower <= n && n < upper)
+
+	default:
+		return errUnknownOpcode
+	}
+	return nil
+}
+
+func (m *machine) hash(op byte) error {
+	value, err := m.pop()
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case OpRipemd160:
+		m.push(crypto.Ripemd160(value))
+	case OpSha1:
+		digest := sha1.Sum(value)
+		m.push(digest[:])
+	case OpSha256:
+		digest := sha256.Sum256(value)
+		m.push(digest[:])
+	case OpHash160:
+		m.push(crypto.Hash160(value))
+	case OpHash256:
+		m.push(crypto.Hash256(value))
+	}
+	return nil
+}
+
+// checkSignature verifies a DER signature, with its sighash type byte, against the context digest
+func (m *machine) checkSignature(sig, pubkey []byte) (bool, error) {
+	if len(sig) == 0 {
+		return false, nil
+	}
+	if len(m.ctx.SigHash) != 32 {
+		return false, errors.New("signature check needs a 32-byte sighash digest")
+	}
+
+	signature, err := secp256k1.ParseDERSignature(sig[:len(sig)-1])
+	if err != nil {
+		return false, nil
+	}
+	key, err := secp256k1.ParsePubKey(pubkey)
+	if err != nil {
+		return false, nil
+	}
+	return signature.Verify(m.ctx.SigHash, key), nil
+}
+
+func (m *machine) checkSig() error {
+	sig, pubkey, err := m.pop2()
+	if err != nil {
+		return err
+	}
+
+	valid, err := m.checkSignature(sig, pubkey)
+	if err != nil {
+		return err
+	}
+	m.pushBool(valid)
+	return nil
+}
+
+func (m *machine) checkMultisig() error {
+	keyCount, err := m.popInt(4)
+	if err != nil {
+		return err
+	}
+	if keyCount < 0 || keyCount > maxPubkeysPerSig {
+		return fmt.Errorf("invalid public key count: %d", keyCount)
+	}
+	// every key counts toward the opcode limit, as in Bitcoin Core
+	if err := m.countOps(int(keyCount)); err != nil {
+		return err
+	}
+	pubkeys, err := m.popN(int(keyCount))
+	if err != nil {
+		return err
+	}
+
+	sigCount, err := m.popInt(4)
+	if err != nil {
+		return err
+	}
+	if sigCount < 0 || sigCount > keyCount {
+		return fmt.Errorf("invalid signature count: %d", sigCount)
+	}
+	sigs, err := m.popN(int(sigCount))
+	if err != nil {
+		return err
+	}
+
+	// the extra item consumed by an old off-by-one bug, which must be empty (BIP147)
+	dummy, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if len(dummy) != 0 {
+		return errors.New("CHECKMULTISIG dummy element must be empty")
+	}
+
+	// signatures must match the keys in order
+	k := 0
+	for _, sig := range sigs {
+		for ; k < len(pubkeys); k++ {
+			valid, err := m.checkSignature(sig, pubkeys[k])
+			if err != nil {
+				return err
+			}
+			if valid {
+				break
+			}
+		}
+		if k == len(pubkeys) {
+			m.pushBool(false)
+			return nil
+		}
+		k++
+	}
+	m.pushBool(true)
+	return nil
+}
+
+// checkLockTime implements BIP65 OP_CHECKLOCKTIMEVERIFY
+func (m *machine) checkLockTime() error {
+	top, err := m.peek(0)
+	if err != nil {
+		return err
+	}
+	lockTime, err := DecodeNumber(top, 5)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case lockTime < 0:
+		return errors.New("negative locktime")
+	case (lockTime < lockTimeThreshold) != (m.ctx.LockTime < lockTimeThreshold):
+		return errors.New("locktime type mismatch (height and time)")
+	case lockTime > int64(m.ctx.LockTime):
+		return fmt.Errorf("locktime requirement not satisfied: %d > %d", lockTime, m.ctx.LockTime)
+	case m.ctx.Sequence == sequenceFinal:
+		return errors.New("input sequence is final, disabling locktime")
+	}
+	return nil
+}
+
+// checkSequence implements BIP112 OP_CHECKSEQUENCEVERIFY
+func (m *machine) checkSequence() error {
+	top, err := m.peek(0)
+	if err != nil {
+		return err
+	}
+	sequence, err := DecodeNumber(top, 5)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case sequence < 0:
+		return errors.New("negative sequence")
+	case sequence&sequenceDisableFlag != 0:
+		return nil
+	case m.ctx.Version < 2:
+		return errors.New("relative locktime needs transaction version 2")
+	case m.ctx.Sequence&sequenceDisableFlag != 0:
+		return errors.New("input sequence has relative locktime disabled")
+	case (sequence&sequenceTypeFlag != 0) != (m.ctx.Sequence&sequenceTypeFlag != 0):
+		return errors.New("relative locktime type mismatch (blocks and time)")
+	case sequence&sequenceMask > int64(m.ctx.Sequence&sequenceMask):
+		return fmt.Errorf("relative locktime requirement not satisfied: %d > %d", sequence&sequenceMask, m.ctx.Sequence&sequenceMask)
+	}
+	return nil
+}
+
+func (m *machine) verify() error {
+	value, err := m.pop()
+	if err != nil {
+		return err
+	}
+	if !castToBool(value) {
+		return errors.New("verify failed")
+	}
+	return nil
+}
+
+func (m *machine) push(value []byte) {
+	m.stack = append(m.stack, value)
+}
+
+func (m *machine) pushInt(n int64) {
+	m.push(EncodeNumber(n))
+}
+
+func (m *machine) pushBool(b bool) {
+	if b {
+		m.push([]byte{1})
+	} else {
+		m.push(nil)
+	}
+}
+
+func (m *machine) peek(depth int) ([]byte, error) {
+	if depth >= len(m.stack) {
+		return nil, errors.New("stack too small")
+	}
+	return m.stack[len(m.stack)-1-depth], nil
+}
+
+func (m *machine) pop() ([]byte, error) {
+	value, err := m.peek(0)
+	if err != nil {
+		return nil, err
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	return value, nil
+}
+
+// pop2 pops the top two items, returning them in stack order
+func (m *machine) pop2() ([]byte, []byte, error) {
+	items, err := m.popN(2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items[0], items[1], nil
+}
+
+// popN pops n items, returning them in stack order (the deepest first)
+func (m *machine) popN(n int) ([][]byte, error) {
+	if n > len(m.stack) {
+		return nil, errors.New("stack too small")
+	}
+	items := append([][]byte{}, m.stack[len(m.stack)-n:]...)
+	m.stack = m.stack[:len(m.stack)-n]
+	return items, nil
+}
+
+func (m *machine) popInt(maxSize int) (int64, error) {
+	value, err := m.pop()
+	if err != nil {
+		return 0, err
+	}
+	return DecodeNumber(value, maxSize)
+}
+
+func (m *machine) drop(n int) error {
+	_, err := m.popN(n)
+	return err
+}
+
+// copyItems pushes a copy of count items starting depth items down
+func (m *machine) copyItems(depth, count int) error {
+	if depth > len(m.stack) {
+		return errors.New("stack too small")
+	}
+	start := len(m.stack) - depth
+	m.stack = append(m.stack, m.stack[start:start+count]...)
+	return nil
+}
+
+// move moves count items starting depth items down to the top of the stack
+func (m *machine) move(depth, count int) error {
+	if depth > len(m.stack) {
+		return errors.New("stack too small")
+	}
+	start := len(m.stack) - depth
+	moved := append([][]byte{}, m.stack[start:start+count]...)
+	m.stack = append(m.stack[:start], m.stack[start+count:]...)
+	m.stack = append(m.stack, moved...)
+	return nil
+}
+
+// castToBool is false for empty data, zeros and negative zero
+func castToBool(value []byte) bool {
+	for i, b := range value {
+		if b != 0 {
+			return !(i == len(value)-1 && b == 0x80)
+		}
+	}
+	return false
+}
+
+func copyStack(stack [][]byte) [][]byte {
+	return append([][]byte{}, stack...)
+}
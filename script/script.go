@@ -0,0 +1,170 @@
+package script
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Instruction is an opcode with the data it pushes, if any
+type Instruction struct {
+	Opcode byte
+	Data   []byte
+}
+
+// Parse splits a script into its instructions
+func Parse(script []byte) ([]Instruction, error) {
+	var instructions []Instruction
+	for i := 0; i < len(script); {
+		opcode := script[i]
+		i++
+
+		size := 0
+		switch {
+		case opcode > Op0 && opcode < OpPushData1:
+			size = int(opcode)
+		case opcode == OpPushData1 && i+1 <= len(script):
+			size = int(script[i])
+			i++
+		case opcode == OpPushData2 && i+2 <= len(script):
+			size = int(binary.LittleEndian.Uint16(script[i:]))
+			i += 2
+		case opcode == OpPushData4 && i+4 <= len(script):
+			size = int(binary.LittleEndian.Uint32(script[i:]))
+			i += 4
+		case opcode >= OpPushData1 && opcode <= OpPushData4:
+			return nil, fmt.Errorf("truncated %s at byte %d", OpcodeName(opcode), i-1)
+		}
+
+		if size > len(script)-i {
+			return nil, fmt.Errorf("push of %d bytes past the end of the script at byte %d", size, i)
+		}
+
+		instruction := Instruction{Opcode: opcode}
+		if opcode > Op0 && opcode <= OpPushData4 {
+			instruction.Data = script[i : i+size]
+		}
+		instructions = append(instructions, instruction)
+		i += size
+	}
+	return instructions, nil
+}
+
+// IsPush reports whether the instruction pushes data or a number (OP_0 to OP_16)
+func (ins Instruction) IsPush() bool {
+	return ins.Opcode <= Op16 && ins.Opcode != OpReserved
+}
+
+// PushData returns the minimal push of data: OP_0, OP_1NEGATE and OP_1 to OP_16 for
+// the values they push, or the smallest pushdata opcode
+func PushData(data []byte) []byte {
+	switch n := len(data); {
+	case n == 0:
+		return []byte{Op0}
+	case n == 1 && data[0] >= 1 && data[0] <= 16:
+		return []byte{SmallInt(int(data[0]))}
+	case n == 1 && data[0] == 0x81:
+		return []byte{Op1Negate}
+	case n < OpPushData1:
+		return append([]byte{byte(n)}, data...)
+	case n <= 0xff:
+		return append([]byte{OpPushData1, byte(n)}, data...)
+	case n <= 0xffff:
+		return append(binary.LittleEndian.AppendUint16([]byte{OpPushData2}, uint16(n)), data...)
+	default:
+		return append(binary.LittleEndian.AppendUint32([]byte{OpPushData4}, uint32(n)), data...)
+	}
+}
+
+// PushInt returns the push of a script number
+func PushInt(n int64) []byte {
+	return PushData(EncodeNumber(n))
+}
+
+// EncodeNumber encodes a script number: little-endian, with the sign in the top bit of the last byte
+func EncodeNumber(n int64) []byte {
+	if n == 0 {
+		return nil
+	}
+
+	negative := n < 0
+	abs := uint64(n)
+	if negative {
+		abs = uint64(-n)
+	}
+
+	var data []byte
+	for ; abs > 0; abs >>= 8 {
+		data = append(data, byte(abs))
+	}
+
+	if data[len(data)-1]&0x80 != 0 {
+		data = append(data, 0x00)
+	}
+	if negative {
+		data[len(data)-1] |= 0x80
+	}
+	return data
+}
+
+// DecodeNumber decodes a minimally encoded script number of at most maxSize bytes
+func DecodeNumber(data []byte, maxSize int) (int64, error) {
+	if len(data) > maxSize {
+		return 0, fmt.Errorf("script number of %d bytes, more than %d", len(data), maxSize)
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	last := data[len(data)-1]
+	if last&0x7f == 0 && (len(data) == 1 || data[len(data)-2]&0x80 == 0) {
+		return 0, errors.New("non-minimally encoded script number")
+	}
+
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << (8 * i)
+	}
+
+	if last&0x80 != 0 {
+		n &^= int64(0x80) << (8 * (len(data) - 1))
+		return -n, nil
+	}
+	return n, nil
+}
+
+// P2PK returns the pay-to-pubkey script: <pubkey> OP_CHECKSIG
+func P2PK(pubkey []byte) []byte {
+	return append(PushData(pubkey), OpCheckSig)
+}
+
+// P2PKH returns the pay-to-pubkey-hash script: OP_DUP OP_HASH160 <hash> OP_EQUALVERIFY OP_CHECKSIG
+func P2PKH(pubkeyHash []byte) []byte {
+	script := append([]byte{OpDup, OpHash160}, PushData(pubkeyHash)...)
+	return append(script, OpEqualVerify, OpCheckSig)
+}
+
+// P2SH returns the pay-to-script-hash script: OP_HASH160 <hash> OP_EQUAL
+func P2SH(scriptHash []byte) []byte {
+	return append(append([]byte{OpHash160}, PushData(scriptHash)...), OpEqual)
+}
+
+// WitnessProgram returns the segwit scriptPubKey of a witness version and program: OP_n <program>
+func WitnessProgram(version int, program []byte) []byte {
+	return append([]byte{SmallInt(version)}, PushData(program)...)
+}
+
+// Multisig returns the bare m-of-n script: <m> <pubkey>... <n> OP_CHECKMULTISIG
+func Multisig(required int, pubkeys [][]byte) []byte {
+	script := PushInt(int64(required))
+	for _, pubkey := range pubkeys {
+		script = append(script, PushData(pubkey)...)
+	}
+	script = append(script, PushInt(int64(len(pubkeys)))...)
+	return append(script, OpCheckMultisig)
+}
+
+// NullData returns the OP_RETURN data carrier script
+func NullData(data []byte) []byte {
+	return append([]byte{OpReturn}, PushData(data)...)
+}
@@ -0,0 +1,269 @@
+package script_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/tx"
+)
+
+func decode(s string) []byte {
+	data, _ := hex.DecodeString(s)
+	return data
+}
+
+type asmTestData struct {
+	asm    string
+	script string
+}
+
+var asmTests = []asmTestData{
+	{"OP_DUP OP_HASH160 751e76e8199196d454941c45d1b3a323f1433bd6 OP_EQUALVERIFY OP_CHECKSIG", "76a914751e76e8199196d454941c45d1b3a323f1433bd688ac"},
+	{"OP_HASH160 bcfeb728b584253d5f3f70bcb780e9ef218a68f4 OP_EQUAL", "a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487"},
+	{"0 751e76e8199196d454941c45d1b3a323f1433bd6", "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+	{"1 da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21", "5120da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21"},
+	{"2 0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798 02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5 2 OP_CHECKMULTISIG", "52210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f817982102c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee552ae"},
+	{"500000 OP_CHECKLOCKTIMEVERIFY OP_DROP -1 17 -200 128 OP_CHECKSEQUENCEVERIFY", "0320a107b1754f011102c880028000b2"},
+	{"OP_RETURN 0100 0x1234567890 68656c6c6f", "6a0201000512345678900568656c6c6f"},
+	{"OP_SIZE 32 OP_EQUALVERIFY OP_SHA256 0000000000000000000000000000000000000000000000000000000000000000 OP_EQUAL", "82012088a820000000000000000000000000000000000000000000000000000000000000000087"},
+}
+
+func TestAssemble(t *testing.T) {
+	for _, test := range asmTests {
+		assembled, err := script.Assemble(test.asm)
+		switch {
+		case err != nil:
+			t.Errorf("Assemble %s FAILED: %v\n", test.asm, err)
+		case hex.EncodeToString(assembled) != test.script:
+			t.Errorf("Assemble %s FAILED. Expected %s, got %x\n", test.asm, test.script, assembled)
+		default:
+			t.Logf("Assemble passed: %s\n", test.script)
+		}
+	}
+
+	for _, lower := range []string{"dup hash160 751e76e8199196d454941c45d1b3a323f1433bd6 equalverify checksig"} {
+		assembled, _ := script.Assemble(lower)
+		if hex.EncodeToString(assembled) != asmTests[0].script {
+			t.Errorf("Assemble %s FAILED. Expected %s, got %x\n", lower, asmTests[0].script, assembled)
+		}
+	}
+
+	for _, invalid := range []string{"OP_DUP OP_NOSUCH", "abc", "0xzz"} {
+		if assembled, err := script.Assemble(invalid); err == nil {
+			t.Errorf("Assemble %s passed, should've failed: %x\n", invalid, assembled)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	for _, test := range asmTests {
+		data, _ := hex.DecodeString(test.script)
+		asm, err := script.Disassemble(data)
+		switch {
+		case err != nil:
+			t.Errorf("Disassemble %s FAILED: %v\n", test.script, err)
+		case asm != test.asm:
+			t.Errorf("Disassemble %s FAILED. Expected %s, got %s\n", test.script, test.asm, asm)
+		default:
+			t.Logf("Disassemble passed: %s\n", test.asm)
+		}
+	}
+
+	for _, invalid := range []string{"14751e76", "4c", "4d0100", "4e05000000aa"} {
+		data, _ := hex.DecodeString(invalid)
+		if asm, err := script.Disassemble(data); err == nil {
+			t.Errorf("Disassemble %s passed, should've failed: %s\n", invalid, asm)
+		}
+	}
+}
+
+func TestPushData(t *testing.T) {
+	tests := map[int]string{0: "00", 1: "01", 75: "4b", 76: "4c4c", 255: "4cff", 256: "4d0001", 65536: "4e00000100"}
+	for size, prefix := range tests {
+		pushed := hex.EncodeToString(script.PushData(make([]byte, size)))
+		if !strings.HasPrefix(pushed, prefix) || len(pushed) != len(prefix)+2*size {
+			t.Errorf("PushData of %d bytes FAILED. Expected prefix %s, got %.12s\n", size, prefix, pushed)
+		} else {
+			t.Logf("PushData passed: %d bytes, %s\n", size, prefix)
+		}
+	}
+}
+
+func TestMultisig(t *testing.T) {
+	pubkey := make([]byte, 33)
+	for _, count := range []int{1, 16, 17, 20} {
+		pubkeys := make([][]byte, count)
+		for i := range pubkeys {
+			pubkeys[i] = pubkey
+		}
+
+		// counts above 16 have no OP_n and are pushed as script numbers
+		multisig := hex.EncodeToString(script.Multisig(1, pubkeys))
+		suffix := hex.EncodeToString(append(script.PushInt(int64(count)), script.OpCheckMultisig))
+		if !strings.HasPrefix(multisig, "5121") || !strings.HasSuffix(multisig, suffix) {
+			t.Errorf("Multisig of %d keys FAILED. Expected 5121...%s, got %s\n", count, suffix, multisig)
+		} else {
+			t.Logf("Multisig passed: %d keys, %s\n", count, suffix)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := map[int64]string{0: "", 1: "01", -1: "81", 127: "7f", 128: "8000", -128: "8080", 255: "ff00", 500000: "20a107", -2147483647: "ffffffff"}
+	for n, expected := range tests {
+		encoded := script.EncodeNumber(n)
+		decoded, err := script.DecodeNumber(encoded, 4)
+		switch {
+		case hex.EncodeToString(encoded) != expected:
+			t.Errorf("EncodeNumber %d FAILED. Expected %s, got %x\n", n, expected, encoded)
+		case err != nil || decoded != n:
+			t.Errorf("DecodeNumber %s FAILED. Expected %d, got %d (%v)\n", expected, n, decoded, err)
+		default:
+			t.Logf("EncodeNumber passed: %d, %s\n", n, expected)
+		}
+	}
+
+	for _, invalid := range []string{"00", "0100", "0180", "0000000001"} {
+		data, _ := hex.DecodeString(invalid)
+		if n, err := script.DecodeNumber(data, 4); err == nil {
+			t.Errorf("DecodeNumber %s passed, should've failed: %d\n", invalid, n)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		script string
+		class  string
+	}{
+		{"76a914751e76e8199196d454941c45d1b3a323f1433bd688ac", script.ClassPubKeyHash},
+		{"a914bcfeb728b584253d5f3f70bcb780e9ef218a68f487", script.ClassScriptHash},
+		{"210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798ac", script.ClassPubKey},
+		{"0014751e76e8199196d454941c45d1b3a323f1433bd6", script.ClassWitnessV0KeyHash},
+		{"0020b4dcb2eee00b7d71c86c08054f0a40b28e6f85572bd79d314accdfb8f30b9f77", script.ClassWitnessV0Script},
+		{"5120da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21", script.ClassWitnessV1Taproot},
+		{"52020001", script.ClassWitnessUnknown},
+		{"52210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f817982102c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee552ae", script.ClassMultisig},
+		{"6a0568656c6c6f", script.ClassNullData},
+		{"0015751e76e8199196d454941c45d1b3a323f1433bd600", script.ClassNonStandard},
+		{"51", script.ClassNonStandard},
+		{"6a76", script.ClassNonStandard},
+		{"53210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f8179852ae", script.ClassNonStandard},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.script)
+		if class, _ := script.Classify(data); class != test.class {
+			t.Errorf("Classify %s FAILED. Expected %s, got %s\n", test.script, test.class, class)
+		} else {
+			t.Logf("Classify passed: %s, %s\n", test.script, test.class)
+		}
+	}
+}
+
+// BIP143 P2SH-P2WPKH example
+const (
+	signedTx    = "01000000000101db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a5477010000001716001479091972186c449eb1ded22b78e40d009bdf0089feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac02473044022047ac8e878352d3ebbde1c94ce3a10d057c24175747116f8288e5d794d12d482f0220217f36a485cae903c713331d877c1f64677e3622ad4010726870540656fe9dcb012103ad1d8e89212f0b92c74d23bb710c00662ad1470198ac48c43f7d6f93a2a2687392040000"
+	prevoutP2SH = "a9144733f37cf4db86fbc2efed2500b4f4e49f31202387"
+)
+
+func TestVerifySegwit(t *testing.T) {
+	signed, _ := tx.Deserialize(decode(signedTx))
+	in := signed.Inputs[0]
+	sigHash, _ := signed.SigHashSegwitV0(0, script.P2PKH(decode("79091972186c449eb1ded22b78e40d009bdf0089")), 1000000000, 1)
+
+	steps := 0
+	ctx := &script.Context{SigHash: sigHash}
+	if err := script.Verify(in.ScriptSig, in.Witness, decode(prevoutP2SH), ctx, func(script.Step) { steps++ }); err != nil {
+		t.Errorf("Verify FAILED: %v\n", err)
+	} else {
+		t.Logf("Verify passed in %d steps\n", steps)
+	}
+
+	ctx.SigHash = make([]byte, 32)
+	if err := script.Verify(in.ScriptSig, in.Witness, decode(prevoutP2SH), ctx, nil); err == nil {
+		t.Errorf("Verify with a wrong sighash passed, should've failed: FAIL\n")
+	}
+	if err := script.Verify(in.ScriptSig, nil, decode(prevoutP2SH), ctx, nil); err == nil {
+		t.Errorf("Verify without witness passed, should've failed: FAIL\n")
+	}
+}
+
+type verifyTestData struct {
+	scriptSig    string
+	scriptPubKey string
+	ctx          script.Context
+	valid        bool
+}
+
+func TestVerify(t *testing.T) {
+	digest := crypto.Hash256([]byte("pick-private"))
+	key1 := keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+	key2 := keys.FromBigInt(big.NewInt(2), chaincfg.MainNet)
+	sign := func(key keys.PrivateKey) string {
+		sig, _ := key.SignECDSA(digest)
+		return hex.EncodeToString(append(sig, 0x01))
+	}
+	sig1, sig2 := sign(key1), sign(key2)
+	pub1, pub2 := hex.EncodeToString(key1.PublicKey()), hex.EncodeToString(key2.PublicKey())
+	multisig := "2 " + pub1 + " " + pub2 + " 2 OP_CHECKMULTISIG"
+	withSig := script.Context{SigHash: digest}
+
+	tests := []verifyTestData{
+		{"2 3", "OP_ADD 5 OP_EQUAL", script.Context{}, true},
+		{"2 3", "OP_ADD 6 OP_EQUAL", script.Context{}, false},
+		{"0", "OP_IF 1 OP_ELSE 0 OP_ENDIF", script.Context{}, false},
+		{"1", "OP_NOTIF OP_RETURN OP_ELSE 7 OP_ENDIF 7 OP_EQUAL", script.Context{}, true},
+		{"0", "OP_IF OP_CAT OP_ENDIF 1", script.Context{}, false},
+		{"1 2 3", "OP_ROT OP_SWAP OP_SUB OP_1ADD -1 OP_EQUALVERIFY OP_DEPTH 1 OP_EQUAL", script.Context{}, true},
+		{"0x80", "1", script.Context{}, true},
+		{"1", "0x80", script.Context{}, false},
+		{"0x616263", "OP_RIPEMD160 8eb208f7e05d987a9b044a8e98c6b087f15a0bfc OP_EQUAL", script.Context{}, true},
+		{"0x616263", "OP_SHA256 ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad OP_EQUAL", script.Context{}, true},
+		{sig1, pub1 + " OP_CHECKSIG", withSig, true},
+		{sig2, pub1 + " OP_CHECKSIG", withSig, false},
+		{sig1, pub1 + " OP_CHECKSIG", script.Context{}, false},
+		{"0 " + sig1 + " " + sig2, multisig, withSig, true},
+		{"0 " + sig2 + " " + sig1, multisig, withSig, false},
+		{"1 " + sig1 + " " + sig2, multisig, withSig, false},
+		{"1", "500000 OP_CHECKLOCKTIMEVERIFY", script.Context{LockTime: 500000}, true},
+		{"1", "500000 OP_CHECKLOCKTIMEVERIFY", script.Context{LockTime: 499999}, false},
+		{"1", "500000 OP_CHECKLOCKTIMEVERIFY", script.Context{LockTime: 500000, Sequence: 0xffffffff}, false},
+		{"1", "1600000000 OP_CHECKLOCKTIMEVERIFY", script.Context{LockTime: 500000}, false},
+		{"1", "144 OP_CHECKSEQUENCEVERIFY", script.Context{Sequence: 144, Version: 2}, true},
+		{"1", "144 OP_CHECKSEQUENCEVERIFY", script.Context{Sequence: 143, Version: 2}, false},
+		{"1", "144 OP_CHECKSEQUENCEVERIFY", script.Context{Sequence: 144, Version: 1}, false},
+		{"1", "144 OP_CHECKSEQUENCEVERIFY", script.Context{Sequence: 144 | 1<<22, Version: 2}, false},
+	}
+
+	for _, test := range tests {
+		scriptSig, _ := script.Assemble(test.scriptSig)
+		scriptPubKey, _ := script.Assemble(test.scriptPubKey)
+		err := script.Verify(scriptSig, nil, scriptPubKey, &test.ctx, nil)
+		if (err == nil) != test.valid {
+			t.Errorf("Verify for %q / %q FAILED. Expected valid %t, got %v\n", test.scriptSig, test.scriptPubKey, test.valid, err)
+		} else {
+			t.Logf("Verify for %q / %q passed: %v\n", test.scriptSig, test.scriptPubKey, err)
+		}
+	}
+}
+
+func TestVerifyMultisigOpCount(t *testing.T) {
+	// 0-of-20 CHECKMULTISIG: 2 opcodes plus 20 keys toward the limit of 201
+	repeat := "0 0" + strings.Repeat(" 1", 20) + " 20 OP_CHECKMULTISIG OP_DROP "
+	for count, valid := range map[int]bool{9: true, 10: false} {
+		scriptPubKey, _ := script.Assemble(strings.Repeat(repeat, count) + "1")
+		err := script.Verify(nil, nil, scriptPubKey, &script.Context{}, nil)
+		if (err == nil) != valid {
+			t.Errorf("Verify for %d CHECKMULTISIGs FAILED. Expected valid %t, got %v\n", count, valid, err)
+		} else {
+			t.Logf("Verify for %d CHECKMULTISIGs passed: %v\n", count, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package script
+
+// Standard script classes, named as in Bitcoin Core
+const (
+	ClassPubKey           = "pubkey"
+	ClassPubKeyHash       = "pubkeyhash"
+	ClassScriptHash       = "scripthash"
+	ClassMultisig         = "multisig"
+	ClassNullData         = "nulldata"
+	ClassWitnessV0KeyHash = "witness_v0_keyhash"
+	ClassWitnessV0Script  = "witness_v0_scripthash"
+	ClassWitnessV1Taproot = "witness_v1_taproot"
+	ClassWitnessUnknown   = "witness_unknown"
+	ClassNonStandard      = "nonstandard"
+)
+
+// Classify returns the standard template a scriptPubKey matches, and the data it commits to:
+// the public key, hash or witness program, the multisig keys, or the OP_RETURN pushes
+func Classify(script []byte) (string, [][]byte) {
+	if version, program, ok := witnessProgram(script); ok {
+		switch {
+		case version == 0 && len(program) == 20:
+			return ClassWitnessV0KeyHash, [][]byte{program}
+		case version == 0 && len(program) == 32:
+			return ClassWitnessV0Script, [][]byte{program}
+		case version == 1 && len(program) == 32:
+			return ClassWitnessV1Taproot, [][]byte{program}
+		case version != 0:
+			return ClassWitnessUnknown, [][]byte{program}
+		}
+		return ClassNonStandard, nil
+	}
+
+	instructions, err := Parse(script)
+	if err != nil || len(instructions) == 0 {
+		return ClassNonStandard, nil
+	}
+
+	switch {
+	case matches(instructions, OpDup, OpHash160, -20, OpEqualVerify, OpCheckSig):
+		return ClassPubKeyHash, [][]byte{instructions[2].Data}
+	case matches(instructions, OpHash160, -20, OpEqual):
+		return ClassScriptHash, [][]byte{instructions[1].Data}
+	case matches(instructions, -33, OpCheckSig), matches(instructions, -65, OpCheckSig):
+		return ClassPubKey, [][]byte{instructions[0].Data}
+	case instructions[0].Opcode == OpReturn:
+		var pushes [][]byte
+		for _, ins := range instructions[1:] {
+			if !ins.IsPush() {
+				return ClassNonStandard, nil
+			}
+			pushes = append(pushes, ins.Data)
+		}
+		return ClassNullData, pushes
+	}
+
+	if pubkeys, ok := multisigKeys(instructions); ok {
+		return ClassMultisig, pubkeys
+	}
+	return ClassNonStandard, nil
+}
+
+// witnessProgram splits a segwit scriptPubKey: a version opcode and a 2 to 40 byte direct push
+func witnessProgram(script []byte) (int, []byte, bool) {
+	if len(script) < 4 || len(script) > 42 || int(script[1]) != len(script)-2 {
+		return 0, nil, false
+	}
+
+	switch {
+	case script[0] == Op0:
+		return 0, script[2:], true
+	case script[0] >= Op1 && script[0] <= Op16:
+		return int(script[0] - Op1 + 1), script[2:], true
+	}
+	return 0, nil, false
+}
+
+// matches compares instructions to a pattern of opcodes, where a negative value is a push of that many bytes
+func matches(instructions []Instruction, pattern ...int) bool {
+	if len(instructions) != len(pattern) {
+		return false
+	}
+
+	for i, p := range pattern {
+		ins := instructions[i]
+		if p < 0 {
+			if ins.Opcode >= OpPushData1 || len(ins.Data) != -p {
+				return false
+			}
+		} else if int(ins.Opcode) != p {
+			return false
+		}
+	}
+	return true
+}
+
+// multisigKeys returns the public keys of an OP_m <pubkey>... OP_n OP_CHECKMULTISIG script
+func multisigKeys(instructions []Instruction) ([][]byte, bool) {
+	count := len(instructions)
+	if count < 4 || instructions[count-1].Opcode != OpCheckMultisig {
+		return nil, false
+	}
+
+	required, total := instructions[0].Opcode, instructions[count-2].Opcode
+	if required < Op1 || required > Op16 || total < required || total > Op16 || int(total-Op1+1) != count-3 {
+		return nil, false
+	}
+
+	var pubkeys [][]byte
+	for _, ins := range instructions[1 : count-2] {
+		if len(ins.Data) != 33 && len(ins.Data) != 65 {
+			return nil, false
+		}
+		pubkeys = append(pubkeys, ins.Data)
+	}
+	return pubkeys, true
+}
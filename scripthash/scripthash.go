@@ -0,0 +1,100 @@
+package scripthash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ottosch/pick-private/base58"
+	"github.com/ottosch/pick-private/bech32"
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/crypto"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/script"
+)
+
+// Outputs is a redeem or witness script, with its P2SH, P2SH-P2WSH and P2WSH forms
+type Outputs struct {
+	script     []byte
+	expression string
+	params     *chaincfg.Params
+}
+
+// New wraps a script whose descriptor expression is expression, like multi(...) or a miniscript
+func New(s []byte, expression string, params *chaincfg.Params) *Outputs {
+	return &Outputs{s, expression, params}
+}
+
+// Script returns the witness script, which is also the redeem script for P2SH
+func (o *Outputs) Script() []byte {
+	return o.script
+}
+
+// Expression returns the descriptor expression of the script
+func (o *Outputs) Expression() string {
+	return o.expression
+}
+
+// ToAddressP2SH returns the P2SH address of the script
+func (o *Outputs) ToAddressP2SH() string {
+	return base58.CheckEncode([]byte{o.params.ScriptHashAddrID}, crypto.Hash160(o.script))
+}
+
+// ToScriptP2SH returns the P2SH scriptPubKey
+func (o *Outputs) ToScriptP2SH() string {
+	return hex.EncodeToString(script.P2SH(crypto.Hash160(o.script)))
+}
+
+// ToAddressP2SHP2WSH returns the P2SH address of the P2WSH script
+func (o *Outputs) ToAddressP2SHP2WSH() string {
+	return base58.CheckEncode([]byte{o.params.ScriptHashAddrID}, crypto.Hash160(o.witnessProgram()))
+}
+
+// ToScriptP2SHP2WSH returns the P2SH-P2WSH scriptPubKey
+func (o *Outputs) ToScriptP2SHP2WSH() string {
+	return hex.EncodeToString(script.P2SH(crypto.Hash160(o.witnessProgram())))
+}
+
+// ToAddressP2WSH returns the bech32 P2WSH address
+func (o *Outputs) ToAddressP2WSH() string {
+	scriptHash := sha256.Sum256(o.script)
+
+	program := make([]int, len(scriptHash))
+	for i, b := range scriptHash {
+		program[i] = int(b)
+	}
+
+	addr, _ := bech32.SegwitAddrEncode(o.params.Bech32HRP, 0, program)
+	return addr
+}
+
+// ToScriptP2WSH returns the P2WSH scriptPubKey
+func (o *Outputs) ToScriptP2WSH() string {
+	return hex.EncodeToString(o.witnessProgram())
+}
+
+// DescriptorP2SH returns the sh() descriptor
+func (o *Outputs) DescriptorP2SH() string {
+	return o.descriptor("sh(%s)")
+}
+
+// DescriptorP2SHP2WSH returns the sh(wsh()) descriptor
+func (o *Outputs) DescriptorP2SHP2WSH() string {
+	return o.descriptor("sh(wsh(%s))")
+}
+
+// DescriptorP2WSH returns the wsh() descriptor
+func (o *Outputs) DescriptorP2WSH() string {
+	return o.descriptor("wsh(%s)")
+}
+
+// witnessProgram returns the P2WSH scriptPubKey: OP_0 <sha256(script)>
+func (o *Outputs) witnessProgram() []byte {
+	scriptHash := sha256.Sum256(o.script)
+	return script.WitnessProgram(0, scriptHash[:])
+}
+
+func (o *Outputs) descriptor(format string) string {
+	desc, _ := descriptor.AddChecksum(fmt.Sprintf(format, o.expression))
+	return desc
+}
@@ -0,0 +1,45 @@
+package scripthash_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/descriptor"
+	"github.com/ottosch/pick-private/script"
+	"github.com/ottosch/pick-private/scripthash"
+)
+
+const (
+	key1 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	key2 = "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"
+)
+
+func TestOutputs(t *testing.T) {
+	pub1, _ := hex.DecodeString(key1)
+	pub2, _ := hex.DecodeString(key2)
+	outputs := scripthash.New(script.Multisig(1, [][]byte{pub1, pub2}), "multi(1,"+key1+","+key2+")", chaincfg.MainNet)
+
+	if address := outputs.ToAddressP2WSH(); address != "bc1qd6e6c86xp56gw8pty8suuqhscpttea2c5m2fggzjkxzk5nl9faks5pehxu" {
+		t.Errorf("ToAddressP2WSH FAILED. Got %s\n", address)
+	}
+
+	// each descriptor must describe the scriptPubKey next to it
+	forms := map[string]string{
+		outputs.DescriptorP2SH():      outputs.ToScriptP2SH(),
+		outputs.DescriptorP2SHP2WSH(): outputs.ToScriptP2SHP2WSH(),
+		outputs.DescriptorP2WSH():     outputs.ToScriptP2WSH(),
+	}
+	for desc, expected := range forms {
+		parsed, err := descriptor.Parse(desc)
+		if err != nil {
+			t.Errorf("Descriptor %s FAILED: %v\n", desc, err)
+			continue
+		}
+		if scriptPubKey, _ := parsed.Script(0); hex.EncodeToString(scriptPubKey) != expected {
+			t.Errorf("Descriptor %s FAILED. Expected %s, got %x\n", desc, expected, scriptPubKey)
+		} else {
+			t.Logf("Descriptor passed: %s, %s\n", desc, expected)
+		}
+	}
+}
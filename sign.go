@@ -0,0 +1,286 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ottosch/pick-private/bip322"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/message"
+)
+
+var messageAddressTypes = map[string]message.AddressType{
+	"legacy-uncompressed": message.P2PKHUncompressed,
+	"legacy":              message.P2PKH,
+	"p2sh-segwit":         message.P2SHP2WPKH,
+	"segwit":              message.P2WPKH,
+}
+
+func runSign(args []string) {
+	flags := flag.NewFlagSet("sign", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s sign [options] private key | extended key | mnemonic\n", os.Args[0])
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s sign -m \"Hello World\" 1\n", os.Args[0])
+		fmt.Printf("  %s sign -m \"Hello World\" -address segwit KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn\n", os.Args[0])
+		fmt.Printf("  %s sign -m \"Hello World\" -address taproot 1\n", os.Args[0])
+		fmt.Printf("  %s sign -digest a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e 1\n", os.Args[0])
+	}
+
+	var messageFlag, addressFlag, digestFlag, auxFlag string
+	var bip322Flag bool
+	flags.StringVar(&messageFlag, "m", "", "message to sign (BIP137 Bitcoin Signed Message, or BIP322)")
+	flags.StringVar(&addressFlag, "address", "legacy", "address type of the signature: legacy, legacy-uncompressed, p2sh-segwit, segwit or taproot")
+	flags.BoolVar(&bip322Flag, "bip322", false, "sign a segwit address with a BIP322 simple signature instead of BIP137. Always used for taproot")
+	flags.StringVar(&digestFlag, "digest", "", "32-byte digest in hex to sign with ECDSA and Schnorr (BIP340), instead of a message")
+	flags.StringVar(&auxFlag, "aux", "", "32-byte auxiliary randomness in hex for the Schnorr signature of -digest. Defaults to zeros")
+	addKeyFlags(flags)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+	parseSigningKey(positional)
+
+	if digestFlag != "" {
+		signDigest(digestFlag, auxFlag)
+		return
+	}
+
+	if addressFlag == "taproot" || bip322Flag {
+		signBip322(addressFlag, messageFlag)
+		return
+	}
+
+	addrType, ok := messageAddressTypes[addressFlag]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unrecognized address type: %s\n", addressFlag)
+		os.Exit(1)
+	}
+
+	signature, err := message.Sign(&privateKey, messageFlag, addrType)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var address string
+	switch addrType {
+	case message.P2PKHUncompressed:
+		address = privateKey.ToAddressLegacyUncompressed()
+	case message.P2PKH:
+		address = privateKey.ToAddressLegacy()
+	case message.P2SHP2WPKH:
+		address = privateKey.ToAddressSegWitCompat()
+	case message.P2WPKH:
+		address = privateKey.ToAddressSegWit()
+	}
+
+	fmt.Println("[Signed message]")
+	fmt.Printf("  Address: %s\n", address)
+	fmt.Printf("  Message: %s\n", messageFlag)
+	fmt.Printf("Signature: %s\n", signature)
+}
+
+func signDigest(digestHex, auxHex string) {
+	digest, err := hex.DecodeString(digestHex)
+	if err != nil || len(digest) != 32 {
+		fmt.Fprintf(os.Stderr, "invalid digest, expected 32 bytes in hex: %s\n", digestHex)
+		os.Exit(1)
+	}
+
+	var auxRand []byte
+	if auxHex != "" {
+		if auxRand, err = hex.DecodeString(auxHex); err != nil || len(auxRand) != 32 {
+			fmt.Fprintf(os.Stderr, "invalid auxiliary randomness, expected 32 bytes in hex: %s\n", auxHex)
+			os.Exit(1)
+		}
+	}
+
+	ecdsa, err := privateKey.SignECDSA(digest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	schnorr, err := privateKey.SignSchnorr(digest, auxRand)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[ECDSA]")
+	fmt.Printf("Public key: %x\n", privateKey.PublicKey())
+	fmt.Printf(" Signature: %x\n", ecdsa)
+	fmt.Println()
+	fmt.Println("[Schnorr (BIP340)]")
+	fmt.Printf("Public key: %x\n", privateKey.TaprootInternalKey())
+	fmt.Printf(" Signature: %x\n", schnorr)
+}
+
+func signBip322(addressType, msg string) {
+	var address, script string
+	switch addressType {
+	case "segwit":
+		address, script = privateKey.ToAddressSegWit(), privateKey.ToScriptSegwit()
+	case "taproot":
+		address, script = privateKey.ToAddressTaproot(), privateKey.ToScriptTaproot()
+	default:
+		fmt.Fprintf(os.Stderr, "BIP322 signing supports segwit and taproot addresses, got %s\n", addressType)
+		os.Exit(1)
+	}
+
+	scriptPubKey, _ := hex.DecodeString(script)
+	signature, err := bip322.Sign(&privateKey, scriptPubKey, msg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[Signed message (BIP322)]")
+	fmt.Printf("  Address: %s\n", address)
+	fmt.Printf("  Message: %s\n", msg)
+	fmt.Printf("Signature: %s\n", signature)
+}
+
+func runVerify(args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s verify [options] address signature\n", os.Args[0])
+		fmt.Printf("       %s verify -digest digest public key signature\n", os.Args[0])
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s verify -m \"Hello World\" 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH IGXH085B9ZEWwQqpO/zC9gtJZVES7DgLOHPONO5mbvCqXPI91aSz+/pYk/HK4w6NSYuzxgRi3qmNs/bTz9Pjr1o=\n", os.Args[0])
+		fmt.Printf("  %s verify -m \"Hello World\" bc1ppv609nr0vr25u07u95waq5lucwfm6tde4nydujnu8npg4q75mr5sxq8lt3 AUHd69PrJQEv+oKTfZ8l+WROBHuy9HKrbFCJu7U1iK2iiEy1vMU5EfMtjc+VSHM7aU0SDbak5IUZRVno2P5mjSafAQ==\n", os.Args[0])
+		fmt.Printf("  %s verify -digest a0dc65ffca799873cbea0ac274015b9526505daaaed385155425f7337704883e 0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798 3045022100934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d802202442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5\n", os.Args[0])
+	}
+
+	var messageFlag, digestFlag, coinFlag, networkFlag string
+	flags.StringVar(&messageFlag, "m", "", "signed message (BIP137 Bitcoin Signed Message, or BIP322)")
+	flags.StringVar(&digestFlag, "digest", "", "32-byte digest in hex signed with ECDSA (DER) or Schnorr (BIP340, 64 bytes), instead of a message")
+	flags.StringVar(&coinFlag, "coin", "btc", coinUsage)
+	flags.StringVar(&networkFlag, "network", "mainnet", networkUsage)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 2 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	if digestFlag != "" {
+		verifyDigest(digestFlag, positional[0], positional[1])
+		return
+	}
+
+	params := lookupNetwork(coinFlag, networkFlag)
+	verify := func() error {
+		return message.Verify(positional[0], messageFlag, positional[1], params)
+	}
+	if sig, err := base64.StdEncoding.DecodeString(positional[1]); err == nil && !isCompactSignature(sig) {
+		verify = func() error {
+			return bip322.Verify(positional[0], messageFlag, positional[1])
+		}
+	}
+
+	if err := verify(); err != nil {
+		fmt.Fprintln(os.Stderr, "Signature is INVALID:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Signature is valid")
+}
+
+func verifyDigest(digestHex, pubkeyHex, signatureHex string) {
+	digest, err1 := hex.DecodeString(digestHex)
+	pubkey, err2 := hex.DecodeString(pubkeyHex)
+	signature, err3 := hex.DecodeString(signatureHex)
+	if err1 != nil || err2 != nil || err3 != nil {
+		fmt.Fprintln(os.Stderr, "digest, public key and signature must be hex")
+		os.Exit(1)
+	}
+
+	var err error
+	if len(signature) == 64 {
+		if len(pubkey) == 33 {
+			pubkey = pubkey[1:]
+		}
+		err = keys.VerifySchnorr(pubkey, digest, signature)
+	} else {
+		err = keys.VerifyECDSA(pubkey, digest, signature)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Signature is INVALID:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Signature is valid")
+}
+
+// isCompactSignature reports whether a signature has the BIP137 length and header byte,
+// telling it apart from a BIP322 witness.
+func isCompactSignature(sig []byte) bool {
+	return len(sig) == 65 && sig[0] >= 27 && sig[0] <= 42
+}
+
+// addKeyFlags adds the options of the key input to a subcommand that signs
+func addKeyFlags(flags *flag.FlagSet) {
+	flags.StringVar(&coinName, "coin", "btc", coinUsage)
+	flags.StringVar(&networkName, "network", "", networkUsage+". Defaults to mainnet, or to the network of an extended key")
+	flags.StringVar(&keyType, "type", "", "force input into a specific type, as in the main command")
+	flags.StringVar(&derivationPath, "path", "", "derivation path for an extended key or mnemonic input")
+	flags.StringVar(&passphrase, "passphrase", "", "optional BIP39 passphrase for a mnemonic input")
+}
+
+// parseSigningKey parses the private key of a subcommand, exiting if it has none.
+// It can be called once per key, as the detected key type is reset.
+func parseSigningKey(positional []string) {
+	keyDecimal, keyBinary, keyHex, keyWif, keyExtended, keyMnemonic, keyBip38, keyPublic = false, false, false, false, false, false, false, false
+	extendedKey = nil
+	parseCliArgs()
+	inputKey = strings.Join(positional, " ")
+	parsePrivateKey()
+
+	if extendedKey != nil && !extendedKey.IsPrivate() {
+		fmt.Fprintln(os.Stderr, "signing needs a private key, got an extended public key")
+		os.Exit(1)
+	}
+	if keyPublic {
+		fmt.Fprintln(os.Stderr, "signing needs a private key, got a public key")
+		os.Exit(1)
+	}
+	if err := keys.CheckPrivateKey(privateKey.PrivateKey()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parsePublicKey parses a public key, or the key a private, extended or mnemonic input derives.
+// A 65-byte public key input stays uncompressed; every other input gives the compressed key.
+func parsePublicKey(positional []string) []byte {
+	keyDecimal, keyBinary, keyHex, keyWif, keyExtended, keyMnemonic, keyBip38, keyPublic = false, false, false, false, false, false, false, false
+	extendedKey = nil
+	parseCliArgs()
+	inputKey = strings.Join(positional, " ")
+	parsePrivateKey()
+
+	switch {
+	case keyPublic && len(inputKey) == 130:
+		return publicKey.PublicKeyUncompressed()
+	case keyPublic:
+		return publicKey.PublicKey()
+	case extendedKey != nil:
+		return extendedKey.PublicKey()
+	default:
+		return privateKey.PublicKey()
+	}
+}
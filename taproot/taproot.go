@@ -0,0 +1,211 @@
+package taproot
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// LeafVersionTapscript is the leaf version for BIP342 tapscript
+const LeafVersionTapscript = 0xc0
+
+// annexTag is the first byte of a witness annex, which a leaf version can't take (BIP341)
+const annexTag = 0x50
+
+// Leaf is a script in a taproot script tree
+type Leaf struct {
+	Version byte
+	Script  []byte
+}
+
+// NewLeaf creates a tapscript leaf (version 0xc0) from a script.
+func NewLeaf(script []byte) Leaf {
+	return Leaf{LeafVersionTapscript, script}
+}
+
+// ParseLeaf parses a leaf given as "script hex" or "version:script hex", version in hex.
+func ParseLeaf(s string) (Leaf, error) {
+	version := uint64(LeafVersionTapscript)
+	if pos := strings.Index(s, ":"); pos >= 0 {
+		var err error
+		if version, err = strconv.ParseUint(s[:pos], 16, 8); err != nil {
+			return Leaf{}, fmt.Errorf("invalid leaf version: %s", s[:pos])
+		}
+		s = s[pos+1:]
+	}
+
+	if version&0x01 != 0 {
+		return Leaf{}, fmt.Errorf("invalid leaf version: %02x is odd", version)
+	}
+	if version == annexTag {
+		return Leaf{}, fmt.Errorf("invalid leaf version: %02x is the annex tag", version)
+	}
+
+	script, err := hex.DecodeString(s)
+	if err != nil {
+		return Leaf{}, fmt.Errorf("invalid leaf script: %s", s)
+	}
+
+	return Leaf{byte(version), script}, nil
+}
+
+// Hash returns the TapLeaf tagged hash of the leaf
+func (leaf Leaf) Hash() []byte {
+	return crypto.TaggedHash("TapLeaf", []byte{leaf.Version}, compactSize(len(leaf.Script)), leaf.Script)
+}
+
+// BranchHash returns the TapBranch tagged hash of two child hashes, sorted lexicographically
+func BranchHash(a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return crypto.TaggedHash("TapBranch", a, b)
+}
+
+// Tree is a taproot script tree, with the merkle path of each leaf
+type Tree struct {
+	leaves []Leaf
+	paths  [][][]byte
+	root   []byte
+}
+
+type node struct {
+	hash   []byte
+	leaves []int
+}
+
+// NewTree builds a balanced script tree, pairing leaves in the given order.
+func NewTree(leaves []Leaf) (*Tree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("script tree needs at least one leaf")
+	}
+
+	tree := &Tree{leaves: leaves, paths: make([][][]byte, len(leaves))}
+	level := make([]node, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = node{leaf.Hash(), []int{i}}
+	}
+
+	for len(level) > 1 {
+		var next []node
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				break
+			}
+
+			left, right := level[i], level[i+1]
+			for _, l := range left.leaves {
+				tree.paths[l] = append(tree.paths[l], right.hash)
+			}
+			for _, l := range right.leaves {
+				tree.paths[l] = append(tree.paths[l], left.hash)
+			}
+
+			branch := BranchHash(left.hash, right.hash)
+			next = append(next, node{branch, append(left.leaves, right.leaves...)})
+		}
+		level = next
+	}
+
+	tree.root = level[0].hash
+	return tree, nil
+}
+
+// Root returns the merkle root of the tree
+func (tree *Tree) Root() []byte {
+	return tree.root
+}
+
+// Leaves returns the leaves of the tree
+func (tree *Tree) Leaves() []Leaf {
+	return tree.leaves
+}
+
+// Path returns the merkle path of the i-th leaf, from the leaf up
+func (tree *Tree) Path(i int) [][]byte {
+	return tree.paths[i]
+}
+
+// TweakPublicKey tweaks an x-only internal key with a merkle root (nil for key-path only).
+// Returns the x-only output key and its parity.
+func TweakPublicKey(internalKey, merkleRoot []byte) ([]byte, byte, error) {
+	curve := secp256k1.S256()
+
+	pubkey, err := secp256k1.ParsePubKey(append([]byte{0x02}, internalKey...))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid internal key: %v", err)
+	}
+
+	tweak := crypto.TaggedHash("TapTweak", internalKey, merkleRoot)
+	if new(big.Int).SetBytes(tweak).Cmp(curve.Params().N) >= 0 {
+		return nil, 0, errors.New("tweak exceeds curve order")
+	}
+
+	tx, ty := curve.ScalarBaseMult(tweak)
+	qx, qy := curve.Add(pubkey.X, pubkey.Y, tx, ty)
+
+	outputKey := make([]byte, 32)
+	qx.FillBytes(outputKey)
+	return outputKey, byte(qy.Bit(0)), nil
+}
+
+// TweakPrivateKey tweaks a 32-byte private key with a merkle root (nil for key-path only),
+// returning the private key of the output key for BIP340 signing.
+func TweakPrivateKey(privateKey, merkleRoot []byte) ([]byte, error) {
+	curve := secp256k1.S256()
+	n := curve.Params().N
+
+	d := new(big.Int).SetBytes(privateKey)
+	if d.Sign() == 0 || d.Cmp(n) >= 0 {
+		return nil, errors.New("invalid private key: out of range")
+	}
+
+	px, py := curve.ScalarBaseMult(privateKey)
+	if py.Bit(0) == 1 {
+		d.Sub(n, d)
+	}
+
+	internalKey := make([]byte, 32)
+	px.FillBytes(internalKey)
+
+	tweak := new(big.Int).SetBytes(crypto.TaggedHash("TapTweak", internalKey, merkleRoot))
+	if tweak.Cmp(n) >= 0 {
+		return nil, errors.New("tweak exceeds curve order")
+	}
+
+	d.Add(d, tweak)
+	d.Mod(d, n)
+	if d.Sign() == 0 {
+		return nil, errors.New("tweaked private key is zero")
+	}
+	return d.FillBytes(make([]byte, 32)), nil
+}
+
+// ControlBlock returns the control block for spending the i-th leaf of the tree
+func ControlBlock(tree *Tree, i int, internalKey []byte, parity byte) []byte {
+	control := []byte{tree.leaves[i].Version | parity}
+	control = append(control, internalKey...)
+	for _, hash := range tree.paths[i] {
+		control = append(control, hash...)
+	}
+	return control
+}
+
+func compactSize(n int) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		return []byte{0xfd, byte(n), byte(n >> 8)}
+	default:
+		return []byte{0xfe, byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+	}
+}
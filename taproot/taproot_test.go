@@ -0,0 +1,156 @@
+package taproot_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1"
+	"github.com/ottosch/pick-private/taproot"
+)
+
+type treeTestData struct {
+	internalKey   string
+	leaves        []string
+	merkleRoot    string
+	outputKey     string
+	parity        byte
+	controlBlocks []string
+}
+
+var treeTests = []treeTestData{
+	{
+		internalKey:   "187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27",
+		leaves:        []string{"20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac"},
+		merkleRoot:    "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21",
+		outputKey:     "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+		parity:        1,
+		controlBlocks: []string{"c1187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27"},
+	},
+	{
+		internalKey: "93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+		leaves:      []string{"c0:20b617298552a72ade070667e86ca63b8f5789a9fe8731ef91202a91c9f3459007ac", "fa:06424950333431"},
+		merkleRoot:  "6d617333d04089655dc10c5891e09d385e7480454ac783afa222ec9ae1485fe8",
+		outputKey:   "f003f7a53b3c696eaa45fd230913fe74e9f4fdbc98c3b6db29638c18ed4cb04a",
+		parity:      0,
+		controlBlocks: []string{
+			"c093478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820f224a923cd0021ab202ab139cc56802ddb92dcfc172b9212261a539df79a112a",
+			"fa93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2b",
+		},
+	},
+	{
+		internalKey: "93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820",
+		leaves:      []string{"20b617298552a72ade070667e86ca63b8f5789a9fe8731ef91202a91c9f3459007ac", "fa:06424950333431", "51"},
+		merkleRoot:  "2a50d450dcb30a61a614eeb67d3abdde1851bc32eb7f22e475f6da9f3ca25378",
+		outputKey:   "b9ea78e3a0cfdeef66a6a93211905ececc22088660cc184277956c5191d6b4bd",
+		parity:      0,
+		controlBlocks: []string{
+			"c093478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820f224a923cd0021ab202ab139cc56802ddb92dcfc172b9212261a539df79a112aa85b2107f791b26a84e7586c28cec7cb61202ed3d01944d832500f363782d675",
+			"fa93478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae78451820c525714a7f49c28aedbbba78c005931a81c234b2f6c99a73e4d06082adc8bf2ba85b2107f791b26a84e7586c28cec7cb61202ed3d01944d832500f363782d675",
+			"c093478e9488f956df2396be2ce6c5cced75f900dfa18e7dabd2428aae784518206d617333d04089655dc10c5891e09d385e7480454ac783afa222ec9ae1485fe8",
+		},
+	},
+}
+
+var invalidLeaves = []string{
+	"c1:51",
+	"50:51",
+	"zz:51",
+	"c0:5",
+	"c0:xx",
+	"100:51",
+}
+
+func TestTree(t *testing.T) {
+	for _, test := range treeTests {
+		var leaves []taproot.Leaf
+		for _, s := range test.leaves {
+			leaf, err := taproot.ParseLeaf(s)
+			if err != nil {
+				t.Fatalf("ParseLeaf for %s FAILED: %v\n", s, err)
+			}
+			leaves = append(leaves, leaf)
+		}
+
+		tree, err := taproot.NewTree(leaves)
+		if err != nil {
+			t.Fatalf("NewTree for %s FAILED: %v\n", test.leaves, err)
+		}
+
+		if root := hex.EncodeToString(tree.Root()); root != test.merkleRoot {
+			t.Errorf("Root for %s FAILED. Expected %s, got %s\n", test.leaves, test.merkleRoot, root)
+		} else {
+			t.Logf("Root passed: %s, %s\n", test.leaves, test.merkleRoot)
+		}
+
+		internalKey, _ := hex.DecodeString(test.internalKey)
+		outputKey, parity, err := taproot.TweakPublicKey(internalKey, tree.Root())
+		switch {
+		case err != nil:
+			t.Errorf("TweakPublicKey for %s FAILED: %v\n", test.internalKey, err)
+		case hex.EncodeToString(outputKey) != test.outputKey || parity != test.parity:
+			t.Errorf("TweakPublicKey for %s FAILED. Expected %s (%d), got %x (%d)\n", test.internalKey, test.outputKey, test.parity, outputKey, parity)
+		default:
+			t.Logf("TweakPublicKey passed: %s, %s\n", test.internalKey, test.outputKey)
+		}
+
+		for i, expected := range test.controlBlocks {
+			control := hex.EncodeToString(taproot.ControlBlock(tree, i, internalKey, parity))
+			if control != expected {
+				t.Errorf("ControlBlock for leaf %d of %s FAILED. Expected %s, got %s\n", i, test.leaves, expected, control)
+			} else {
+				t.Logf("ControlBlock passed: leaf %d, %s\n", i, expected)
+			}
+		}
+	}
+}
+
+func TestParseLeafInvalid(t *testing.T) {
+	for _, test := range invalidLeaves {
+		if _, err := taproot.ParseLeaf(test); err == nil {
+			t.Errorf("ParseLeaf for %s passed, should've failed: FAIL\n", test)
+		} else {
+			t.Logf("ParseLeaf for %s failed: %v\n", test, err)
+		}
+	}
+}
+
+func TestTreeDepth(t *testing.T) {
+	leaves := make([]taproot.Leaf, 200)
+	for i := range leaves {
+		leaves[i] = taproot.NewLeaf([]byte{byte(i)})
+	}
+
+	tree, err := taproot.NewTree(leaves)
+	switch {
+	case err != nil:
+		t.Errorf("NewTree for %d leaves FAILED: %v\n", len(leaves), err)
+	case len(tree.Path(0)) != 8:
+		t.Errorf("NewTree for %d leaves FAILED. Expected depth 8, got %d\n", len(leaves), len(tree.Path(0)))
+	default:
+		t.Logf("NewTree passed: %d leaves, depth %d\n", len(leaves), len(tree.Path(0)))
+	}
+}
+
+func TestTweakPublicKeyInvalid(t *testing.T) {
+	internalKey, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000005")
+	if _, _, err := taproot.TweakPublicKey(internalKey, nil); err == nil {
+		t.Errorf("TweakPublicKey for point off the curve passed, should've failed: FAIL\n")
+	}
+}
+
+func TestTweakPrivateKey(t *testing.T) {
+	privateKey, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000001")
+	expected := "da4710964f7852695de2da025290e24af6d8c281de5a0b902b7135fd9fd74d21"
+
+	tweaked, err := taproot.TweakPrivateKey(privateKey, nil)
+	if err != nil {
+		t.Fatalf("TweakPrivateKey FAILED: %v\n", err)
+	}
+
+	x, _ := secp256k1.S256().ScalarBaseMult(tweaked)
+	if result := hex.EncodeToString(x.FillBytes(make([]byte, 32))); result != expected {
+		t.Errorf("TweakPrivateKey FAILED. Expected output key %s, got %s\n", expected, result)
+	} else {
+		t.Logf("TweakPrivateKey passed: %s\n", result)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ottosch/pick-private/address"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/tx"
+)
+
+var sigHashTypes = map[string]byte{
+	"all":                 tx.SigHashAll,
+	"none":                tx.SigHashNone,
+	"single":              tx.SigHashSingle,
+	"all-anyonecanpay":    tx.SigHashAll | tx.SigHashAnyoneCanPay,
+	"none-anyonecanpay":   tx.SigHashNone | tx.SigHashAnyoneCanPay,
+	"single-anyonecanpay": tx.SigHashSingle | tx.SigHashAnyoneCanPay,
+}
+
+// sigHashName returns the -sighash name of a hash type, or default for taproot SIGHASH_DEFAULT
+func sigHashName(hashType byte) string {
+	for name, value := range sigHashTypes {
+		if value == hashType {
+			return name
+		}
+	}
+	if hashType == tx.SigHashDefault {
+		return "default"
+	}
+	return fmt.Sprintf("%02x", hashType)
+}
+
+func runTx(args []string) {
+	flags := flag.NewFlagSet("tx", flag.ExitOnError)
+	flags.SetOutput(os.Stdout)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s tx [options] -prevout amount:script ... -key key ... raw transaction\n", os.Args[0])
+		fmt.Println("\nSigns the P2PKH, P2SH-P2WPKH, P2WPKH and P2TR inputs of the keys.")
+		fmt.Println("\nOptions:")
+		flags.PrintDefaults()
+
+		fmt.Println("\nExamples:")
+		fmt.Printf("  %s tx -prevout 100000:bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4 -key 1 0100000001...\n", os.Args[0])
+		fmt.Printf("  %s tx -prevout 100000:0014751e76e8199196d454941c45d1b3a323f1433bd6 -prevout 50000:bc1p... -key 1 -key 2 0200000002...\n", os.Args[0])
+	}
+
+	var prevoutFlags, keyFlags stringList
+	var sigHashFlag string
+	flags.Var(&prevoutFlags, "prevout", "amount in satoshis and scriptPubKey (hex or address) of an input's output being spent, as amount:script. One per input, in order")
+	flags.Var(&keyFlags, "key", "private key to sign with, in any format the main command takes. Can be repeated")
+	flags.StringVar(&sigHashFlag, "sighash", "all", "signature hash type: all, none, single, or one of them with -anyonecanpay, like all-anyonecanpay")
+	addKeyFlags(flags)
+
+	positional := parseInterspersed(flags, args)
+	if len(positional) != 1 || len(keyFlags) == 0 {
+		flags.Usage()
+		os.Exit(1)
+	}
+
+	raw, err := hex.DecodeString(positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "invalid transaction hex:", err)
+		os.Exit(1)
+	}
+	transaction, err := tx.Deserialize(raw)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	hashType, ok := sigHashTypes[strings.ToLower(sigHashFlag)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unrecognized sighash type: %s\n", sigHashFlag)
+		os.Exit(1)
+	}
+
+	var prevouts []tx.Output
+	for _, value := range prevoutFlags {
+		prevout, err := parsePrevout(value)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		prevouts = append(prevouts, prevout)
+	}
+
+	var privKeys []keys.PrivateKey
+	for _, key := range keyFlags {
+		parseSigningKey([]string{key})
+		privKeys = append(privKeys, privateKey)
+	}
+
+	signed, err := transaction.Sign(prevouts, privKeys, hashType)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println("[Transaction]")
+	fmt.Printf("  TxID: %x\n", transaction.TxID())
+	fmt.Printf("Signed: %d of %d inputs\n", signed, len(transaction.Inputs))
+	fmt.Printf("   Hex: %x\n", transaction.Serialize())
+}
+
+// parsePrevout parses an amount:script value, the script given as hex or as an address
+func parsePrevout(value string) (tx.Output, error) {
+	amount, script, found := strings.Cut(value, ":")
+	if !found {
+		return tx.Output{}, fmt.Errorf("invalid prevout, expected amount:script: %s", value)
+	}
+
+	satoshis, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil || satoshis < 0 {
+		return tx.Output{}, fmt.Errorf("invalid prevout amount: %s", amount)
+	}
+
+	scriptPubKey, err := hex.DecodeString(script)
+	if err != nil {
+		decoded, err := address.Decode(script)
+		if err != nil {
+			return tx.Output{}, fmt.Errorf("invalid prevout script or address: %s", script)
+		}
+		scriptPubKey = decoded.Script
+	}
+
+	return tx.Output{Value: satoshis, Script: scriptPubKey}, nil
+}
@@ -0,0 +1,138 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Deserialize parses a transaction in wire format, with or without witnesses (BIP144)
+func Deserialize(data []byte) (*Tx, error) {
+	reader := bytes.NewReader(data)
+	tx := &Tx{}
+
+	if err := binary.Read(reader, binary.LittleEndian, &tx.Version); err != nil {
+		return nil, errors.New("invalid transaction: truncated version")
+	}
+
+	inputCount, err := ReadCompactSize(reader)
+	if err != nil {
+		return nil, errors.New("invalid transaction: truncated input count")
+	}
+
+	witness := false
+	if inputCount == 0 {
+		flag, err := reader.ReadByte()
+		if err != nil || flag != 0x01 {
+			return nil, errors.New("invalid transaction: no inputs")
+		}
+		witness = true
+
+		if inputCount, err = ReadCompactSize(reader); err != nil {
+			return nil, errors.New("invalid transaction: truncated input count")
+		}
+	}
+
+	for i := 0; i < inputCount; i++ {
+		in := Input{PrevHash: make([]byte, 32)}
+		if _, err := io.ReadFull(reader, in.PrevHash); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated input %d", i)
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &in.PrevIndex); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated input %d", i)
+		}
+		if in.ScriptSig, err = readBytes(reader); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated input %d", i)
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &in.Sequence); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated input %d", i)
+		}
+		tx.Inputs = append(tx.Inputs, in)
+	}
+
+	outputCount, err := ReadCompactSize(reader)
+	if err != nil {
+		return nil, errors.New("invalid transaction: truncated output count")
+	}
+
+	for i := 0; i < outputCount; i++ {
+		var out Output
+		if err := binary.Read(reader, binary.LittleEndian, &out.Value); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated output %d", i)
+		}
+		if out.Script, err = readBytes(reader); err != nil {
+			return nil, fmt.Errorf("invalid transaction: truncated output %d", i)
+		}
+		tx.Outputs = append(tx.Outputs, out)
+	}
+
+	if witness {
+		for i := range tx.Inputs {
+			count, err := ReadCompactSize(reader)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transaction: truncated witness %d", i)
+			}
+
+			for j := 0; j < count; j++ {
+				item, err := readBytes(reader)
+				if err != nil {
+					return nil, fmt.Errorf("invalid transaction: truncated witness %d", i)
+				}
+				tx.Inputs[i].Witness = append(tx.Inputs[i].Witness, item)
+			}
+		}
+	}
+
+	if err := binary.Read(reader, binary.LittleEndian, &tx.LockTime); err != nil {
+		return nil, errors.New("invalid transaction: truncated locktime")
+	}
+	if reader.Len() != 0 {
+		return nil, fmt.Errorf("invalid transaction: %d bytes of trailing data", reader.Len())
+	}
+
+	return tx, nil
+}
+
+// DeserializeWitness parses a witness stack in wire format
+func DeserializeWitness(data []byte) ([][]byte, error) {
+	reader := bytes.NewReader(data)
+	count, err := ReadCompactSize(reader)
+	if err != nil {
+		return nil, errors.New("invalid witness")
+	}
+
+	// every item takes at least one byte, so a larger count can't be read
+	if count > reader.Len() {
+		return nil, errors.New("invalid witness: item count exceeds data")
+	}
+
+	var witness [][]byte
+	for i := 0; i < count; i++ {
+		item, err := readBytes(reader)
+		if err != nil {
+			return nil, errors.New("invalid witness")
+		}
+		witness = append(witness, item)
+	}
+
+	if reader.Len() != 0 {
+		return nil, errors.New("invalid witness: trailing data")
+	}
+	return witness, nil
+}
+
+func readBytes(reader *bytes.Reader) ([]byte, error) {
+	size, err := ReadCompactSize(reader)
+	if err != nil {
+		return nil, err
+	}
+	if size > reader.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(reader, data)
+	return data, err
+}
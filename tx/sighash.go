@@ -0,0 +1,194 @@
+package tx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// Signature hash types
+const (
+	SigHashDefault      byte = 0x00
+	SigHashAll          byte = 0x01
+	SigHashNone         byte = 0x02
+	SigHashSingle       byte = 0x03
+	SigHashAnyoneCanPay byte = 0x80
+)
+
+// SigHashLegacy returns the signature hash of an input spending a pre-segwit output.
+// subScript is the script being executed, like the P2PKH scriptPubKey or a P2SH redeem script.
+func (tx *Tx) SigHashLegacy(index int, subScript []byte, hashType byte) ([]byte, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index out of range: %d", index)
+	}
+
+	baseType := hashType & 0x1f
+	if baseType == SigHashSingle && index >= len(tx.Outputs) {
+		// consensus quirk: the hash of SIGHASH_SINGLE without a matching output is 1
+		one := make([]byte, 32)
+		one[0] = 0x01
+		return one, nil
+	}
+
+	txCopy := Tx{Version: tx.Version, LockTime: tx.LockTime}
+	for i, in := range tx.Inputs {
+		in.ScriptSig, in.Witness = nil, nil
+		if i == index {
+			in.ScriptSig = subScript
+		} else if baseType == SigHashNone || baseType == SigHashSingle {
+			in.Sequence = 0
+		}
+		txCopy.Inputs = append(txCopy.Inputs, in)
+	}
+	if hashType&SigHashAnyoneCanPay != 0 {
+		txCopy.Inputs = txCopy.Inputs[index : index+1]
+	}
+
+	switch baseType {
+	case SigHashNone:
+	case SigHashSingle:
+		for i := 0; i < index; i++ {
+			txCopy.Outputs = append(txCopy.Outputs, Output{Value: -1})
+		}
+		txCopy.Outputs = append(txCopy.Outputs, tx.Outputs[index])
+	default:
+		txCopy.Outputs = tx.Outputs
+	}
+
+	data := txCopy.SerializeNoWitness()
+	data = binary.LittleEndian.AppendUint32(data, uint32(hashType))
+	return crypto.Hash256(data), nil
+}
+
+// SigHashSegwitV0 returns the BIP143 signature hash of an input spending a witness v0 output.
+// scriptCode is the script being executed, like 76a914{hash}88ac for P2WPKH.
+func (tx *Tx) SigHashSegwitV0(index int, scriptCode []byte, amount int64, hashType byte) ([]byte, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index out of range: %d", index)
+	}
+
+	anyoneCanPay := hashType&SigHashAnyoneCanPay != 0
+	baseType := hashType & 0x1f
+
+	hashPrevouts := make([]byte, 32)
+	hashSequence := make([]byte, 32)
+	hashOutputs := make([]byte, 32)
+
+	if !anyoneCanPay {
+		hashPrevouts = crypto.Hash256(tx.prevouts())
+		if baseType != SigHashSingle && baseType != SigHashNone {
+			hashSequence = crypto.Hash256(tx.sequences())
+		}
+	}
+
+	switch {
+	case baseType != SigHashSingle && baseType != SigHashNone:
+		hashOutputs = crypto.Hash256(tx.outputs())
+	case baseType == SigHashSingle && index < len(tx.Outputs):
+		hashOutputs = crypto.Hash256(tx.Outputs[index].serialize())
+	}
+
+	in := tx.Inputs[index]
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, tx.Version)
+	buf.Write(hashPrevouts)
+	buf.Write(hashSequence)
+	buf.Write(in.outpoint())
+	writeBytes(&buf, scriptCode)
+	binary.Write(&buf, binary.LittleEndian, amount)
+	binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	buf.Write(hashOutputs)
+	binary.Write(&buf, binary.LittleEndian, tx.LockTime)
+	binary.Write(&buf, binary.LittleEndian, uint32(hashType))
+
+	return crypto.Hash256(buf.Bytes()), nil
+}
+
+// SigHashTaproot returns the BIP341 signature hash of a key-path spend.
+// prevouts holds the amount and scriptPubKey of every input's output being spent.
+func (tx *Tx) SigHashTaproot(index int, prevouts []Output, hashType byte) ([]byte, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index out of range: %d", index)
+	}
+	if len(prevouts) != len(tx.Inputs) {
+		return nil, fmt.Errorf("expected %d prevouts, got %d", len(tx.Inputs), len(prevouts))
+	}
+
+	anyoneCanPay := hashType&SigHashAnyoneCanPay != 0
+	baseType := hashType & 0x03
+	if hashType > 0x03 && (hashType < 0x81 || hashType > 0x83) {
+		return nil, fmt.Errorf("invalid taproot sighash type: %02x", hashType)
+	}
+	if baseType == SigHashSingle && index >= len(tx.Outputs) {
+		return nil, fmt.Errorf("SIGHASH_SINGLE without a matching output for input %d", index)
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x00, hashType})
+	binary.Write(&buf, binary.LittleEndian, tx.Version)
+	binary.Write(&buf, binary.LittleEndian, tx.LockTime)
+
+	if !anyoneCanPay {
+		var amounts, scripts bytes.Buffer
+		for _, prevout := range prevouts {
+			binary.Write(&amounts, binary.LittleEndian, prevout.Value)
+			writeBytes(&scripts, prevout.Script)
+		}
+
+		buf.Write(sha256Sum(tx.prevouts()))
+		buf.Write(sha256Sum(amounts.Bytes()))
+		buf.Write(sha256Sum(scripts.Bytes()))
+		buf.Write(sha256Sum(tx.sequences()))
+	}
+	if baseType != SigHashNone && baseType != SigHashSingle {
+		buf.Write(sha256Sum(tx.outputs()))
+	}
+
+	buf.WriteByte(0x00) // spend type: key path, no annex
+	if anyoneCanPay {
+		in := tx.Inputs[index]
+		buf.Write(in.outpoint())
+		binary.Write(&buf, binary.LittleEndian, prevouts[index].Value)
+		writeBytes(&buf, prevouts[index].Script)
+		binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	} else {
+		binary.Write(&buf, binary.LittleEndian, uint32(index))
+	}
+	if baseType == SigHashSingle {
+		buf.Write(sha256Sum(tx.Outputs[index].serialize()))
+	}
+
+	return crypto.TaggedHash("TapSighash", buf.Bytes()), nil
+}
+
+func (tx *Tx) prevouts() []byte {
+	var buf bytes.Buffer
+	for _, in := range tx.Inputs {
+		buf.Write(in.outpoint())
+	}
+	return buf.Bytes()
+}
+
+func (tx *Tx) sequences() []byte {
+	var buf bytes.Buffer
+	for _, in := range tx.Inputs {
+		binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	}
+	return buf.Bytes()
+}
+
+func (tx *Tx) outputs() []byte {
+	var buf bytes.Buffer
+	for _, out := range tx.Outputs {
+		buf.Write(out.serialize())
+	}
+	return buf.Bytes()
+}
+
+func sha256Sum(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
@@ -0,0 +1,117 @@
+package tx
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/script"
+)
+
+// Sign signs every input whose prevout scriptPubKey is the P2PKH, P2SH-P2WPKH, P2WPKH or
+// P2TR script of one of the keys, returning how many inputs were signed.
+// prevouts holds the amount and scriptPubKey of every input's output being spent.
+// With SIGHASH_ALL, P2TR inputs use SIGHASH_DEFAULT (64-byte signatures).
+func (tx *Tx) Sign(prevouts []Output, privKeys []keys.PrivateKey, hashType byte) (int, error) {
+	if len(prevouts) != len(tx.Inputs) {
+		return 0, fmt.Errorf("expected %d prevouts, got %d", len(tx.Inputs), len(prevouts))
+	}
+
+	signed := 0
+	for i := range tx.Inputs {
+		for k := range privKeys {
+			ok, err := tx.signInput(i, prevouts, &privKeys[k], hashType)
+			if err != nil {
+				return signed, fmt.Errorf("input %d: %v", i, err)
+			}
+			if ok {
+				signed++
+				break
+			}
+		}
+	}
+	return signed, nil
+}
+
+func (tx *Tx) signInput(index int, prevouts []Output, priv *keys.PrivateKey, hashType byte) (bool, error) {
+	in := &tx.Inputs[index]
+	prevout := prevouts[index]
+
+	switch hex.EncodeToString(prevout.Script) {
+	case priv.ToScriptLegacy():
+		signature, err := tx.signLegacy(index, prevout.Script, priv, hashType)
+		if err != nil {
+			return false, err
+		}
+		in.ScriptSig = append(script.PushData(signature), script.PushData(priv.PublicKey())...)
+
+	case priv.ToScriptLegacyUncompressed():
+		signature, err := tx.signLegacy(index, prevout.Script, priv, hashType)
+		if err != nil {
+			return false, err
+		}
+		in.ScriptSig = append(script.PushData(signature), script.PushData(priv.PublicKeyUncompressed())...)
+
+	case priv.ToScriptSegwitCompat():
+		in.ScriptSig = script.PushData(script.WitnessProgram(0, priv.ToPublicKeyHash()))
+		if err := tx.signSegwitV0(index, prevout.Value, priv, hashType); err != nil {
+			return false, err
+		}
+
+	case priv.ToScriptSegwit():
+		if err := tx.signSegwitV0(index, prevout.Value, priv, hashType); err != nil {
+			return false, err
+		}
+
+	case priv.ToScriptTaproot():
+		if hashType == SigHashAll {
+			hashType = SigHashDefault
+		}
+		sighash, err := tx.SigHashTaproot(index, prevouts, hashType)
+		if err != nil {
+			return false, err
+		}
+
+		signature, err := priv.SignTaproot(sighash, nil)
+		if err != nil {
+			return false, err
+		}
+		if hashType != SigHashDefault {
+			signature = append(signature, hashType)
+		}
+		in.Witness = [][]byte{signature}
+
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (tx *Tx) signLegacy(index int, subScript []byte, priv *keys.PrivateKey, hashType byte) ([]byte, error) {
+	sighash, err := tx.SigHashLegacy(index, subScript, hashType)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := priv.SignECDSA(sighash)
+	if err != nil {
+		return nil, err
+	}
+	return append(signature, hashType), nil
+}
+
+func (tx *Tx) signSegwitV0(index int, amount int64, priv *keys.PrivateKey, hashType byte) error {
+	scriptCode, _ := hex.DecodeString(priv.ToScriptLegacy())
+	sighash, err := tx.SigHashSegwitV0(index, scriptCode, amount, hashType)
+	if err != nil {
+		return err
+	}
+
+	signature, err := priv.SignECDSA(sighash)
+	if err != nil {
+		return err
+	}
+	tx.Inputs[index].Witness = [][]byte{append(signature, hashType), priv.PublicKey()}
+	return nil
+}
@@ -0,0 +1,177 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ottosch/pick-private/crypto"
+)
+
+// Input is a transaction input
+type Input struct {
+	PrevHash  []byte // previous txid, in internal byte order
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+// Output is a transaction output, also used for the prevouts being spent
+type Output struct {
+	Value  int64
+	Script []byte
+}
+
+// Tx is a bitcoin transaction
+type Tx struct {
+	Version  int32
+	Inputs   []Input
+	Outputs  []Output
+	LockTime uint32
+}
+
+// HasWitness reports whether any input has a witness
+func (tx *Tx) HasWitness() bool {
+	for _, in := range tx.Inputs {
+		if len(in.Witness) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Serialize returns the transaction in wire format, with witnesses (BIP144) if it has any.
+func (tx *Tx) Serialize() []byte {
+	return tx.serialize(tx.HasWitness())
+}
+
+// SerializeNoWitness returns the transaction in wire format without witnesses
+func (tx *Tx) SerializeNoWitness() []byte {
+	return tx.serialize(false)
+}
+
+func (tx *Tx) serialize(witness bool) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, tx.Version)
+	if witness {
+		buf.Write([]byte{0x00, 0x01})
+	}
+
+	buf.Write(CompactSize(len(tx.Inputs)))
+	for _, in := range tx.Inputs {
+		buf.Write(in.outpoint())
+		writeBytes(&buf, in.ScriptSig)
+		binary.Write(&buf, binary.LittleEndian, in.Sequence)
+	}
+
+	buf.Write(CompactSize(len(tx.Outputs)))
+	for _, out := range tx.Outputs {
+		buf.Write(out.serialize())
+	}
+
+	if witness {
+		for _, in := range tx.Inputs {
+			buf.Write(SerializeWitness(in.Witness))
+		}
+	}
+
+	binary.Write(&buf, binary.LittleEndian, tx.LockTime)
+	return buf.Bytes()
+}
+
+// TxID returns the transaction id, in the usual reversed (display) byte order
+func (tx *Tx) TxID() []byte {
+	return Reverse(tx.Hash())
+}
+
+// Hash returns the double SHA-256 of the transaction without witnesses, in internal byte order
+func (tx *Tx) Hash() []byte {
+	return crypto.Hash256(tx.SerializeNoWitness())
+}
+
+// SerializeWitness returns a witness stack in wire format
+func SerializeWitness(witness [][]byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(CompactSize(len(witness)))
+	for _, item := range witness {
+		writeBytes(&buf, item)
+	}
+	return buf.Bytes()
+}
+
+// CompactSize returns the variable length integer encoding of n
+func CompactSize(n int) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		return []byte{0xfd, byte(n), byte(n >> 8)}
+	case n <= 0xffffffff:
+		return []byte{0xfe, byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+	default:
+		return append([]byte{0xff}, binary.LittleEndian.AppendUint64(nil, uint64(n))...)
+	}
+}
+
+func (in *Input) outpoint() []byte {
+	outpoint := make([]byte, 32, 36)
+	copy(outpoint, in.PrevHash)
+	return binary.LittleEndian.AppendUint32(outpoint, in.PrevIndex)
+}
+
+func (out *Output) serialize() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, out.Value)
+	writeBytes(&buf, out.Script)
+	return buf.Bytes()
+}
+
+func writeBytes(buf *bytes.Buffer, data []byte) {
+	buf.Write(CompactSize(len(data)))
+	buf.Write(data)
+}
+
+// Reverse returns the bytes in reverse order, as between txids and internal hashes
+func Reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+	return reversed
+}
+
+// ReadCompactSize reads a variable length integer
+func ReadCompactSize(reader io.ByteReader) (int, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	size := 0
+	switch first {
+	case 0xfd:
+		size = 2
+	case 0xfe:
+		size = 4
+	case 0xff:
+		size = 8
+	default:
+		return int(first), nil
+	}
+
+	var n uint64
+	for i := 0; i < size; i++ {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= uint64(b) << (8 * i)
+	}
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("compact size too large: %d", n)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,216 @@
+package tx_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ottosch/pick-private/chaincfg"
+	"github.com/ottosch/pick-private/keys"
+	"github.com/ottosch/pick-private/tx"
+)
+
+type signTestData struct {
+	name       string
+	unsigned   string
+	prevouts   []tx.Output
+	privateKey string
+	index      int
+	sighash    string
+	signed     string
+}
+
+func decode(s string) []byte {
+	data, _ := hex.DecodeString(s)
+	return data
+}
+
+// BIP143 examples: native P2WPKH (the P2PK input is left unsigned) and P2SH-P2WPKH,
+// plus the latter spending a P2PKH output of key 1 instead
+var signTests = []signTestData{
+	{
+		"P2WPKH",
+		"0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000",
+		[]tx.Output{
+			{Value: 625000000, Script: decode("2103c9f4836b9a4f77fc0d81f7bcb01b7f1b35916864b9476c241ce9fc198bd25432ac")},
+			{Value: 600000000, Script: decode("00141d0f172a0ecb48aee1be1f2687d2963ae33f71a1")},
+		},
+		"619c335025c7f4012e556c2a58b2506e30b8511b53ade95ea316fd8c3286feb9",
+		1,
+		"c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670",
+		"01000000000102fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac000247304402203609e17b84f6a7d30c80bfa610b5b4542f32a8a0d5447a12fb1366d7f01cc44a0220573a954c4518331561406f90300e8f3358f51928d43c212a8caed02de67eebee0121025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeee635711000000",
+	},
+	{
+		"P2SH-P2WPKH",
+		"0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000",
+		[]tx.Output{
+			{Value: 1000000000, Script: decode("a9144733f37cf4db86fbc2efed2500b4f4e49f31202387")},
+		},
+		"eb696a065ef48a2192da5b28b694f87544b30fae8327c4510137a922f32c6dcf",
+		0,
+		"64f3b0f4dd2bb3aa1ce8566d220cc74dda9df97d8490cc81d89d735c92e59fb6",
+		"01000000000101db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a5477010000001716001479091972186c449eb1ded22b78e40d009bdf0089feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac02473044022047ac8e878352d3ebbde1c94ce3a10d057c24175747116f8288e5d794d12d482f0220217f36a485cae903c713331d877c1f64677e3622ad4010726870540656fe9dcb012103ad1d8e89212f0b92c74d23bb710c00662ad1470198ac48c43f7d6f93a2a2687392040000",
+	},
+	{
+		"P2PKH",
+		"0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000",
+		[]tx.Output{
+			{Value: 1000000000, Script: decode("76a914751e76e8199196d454941c45d1b3a323f1433bd688ac")},
+		},
+		"0000000000000000000000000000000000000000000000000000000000000001",
+		0,
+		"",
+		"0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a5477010000006b483045022100c21f7d96e3b92d194f34e6e2ead820315991d8c9c6b340a07102efd856afdaa002204c79f5ce1114a59266e55325d863bf880a7d2e8c3bb98c14a4176d20480603ad01210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000",
+	},
+}
+
+func TestSigHashSegwitV0(t *testing.T) {
+	for _, test := range signTests {
+		if test.sighash == "" {
+			continue
+		}
+
+		transaction, err := tx.Deserialize(decode(test.unsigned))
+		if err != nil {
+			t.Fatalf("Deserialize for %s FAILED: %v\n", test.name, err)
+		}
+
+		number, _ := new(big.Int).SetString(test.privateKey, 16)
+		privateKey := keys.FromBigInt(number, chaincfg.MainNet)
+		sighash, err := transaction.SigHashSegwitV0(test.index, decode(privateKey.ToScriptLegacy()), test.prevouts[test.index].Value, tx.SigHashAll)
+
+		switch {
+		case err != nil:
+			t.Errorf("SigHashSegwitV0 for %s FAILED: %v\n", test.name, err)
+		case hex.EncodeToString(sighash) != test.sighash:
+			t.Errorf("SigHashSegwitV0 for %s FAILED. Expected %s, got %x\n", test.name, test.sighash, sighash)
+		default:
+			t.Logf("SigHashSegwitV0 passed: %s, %s\n", test.name, test.sighash)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	for _, test := range signTests {
+		transaction, _ := tx.Deserialize(decode(test.unsigned))
+		number, _ := new(big.Int).SetString(test.privateKey, 16)
+		privateKey := keys.FromBigInt(number, chaincfg.MainNet)
+
+		signed, err := transaction.Sign(test.prevouts, []keys.PrivateKey{privateKey}, tx.SigHashAll)
+		result := hex.EncodeToString(transaction.Serialize())
+		switch {
+		case err != nil:
+			t.Errorf("Sign for %s FAILED: %v\n", test.name, err)
+		case signed != 1 || result != test.signed:
+			t.Errorf("Sign for %s FAILED. Expected %s, got %s (%d signed)\n", test.name, test.signed, result, signed)
+		default:
+			t.Logf("Sign passed: %s\n", test.name)
+		}
+	}
+}
+
+func TestDeserialize(t *testing.T) {
+	for _, test := range signTests {
+		for _, raw := range []string{test.unsigned, test.signed} {
+			transaction, err := tx.Deserialize(decode(raw))
+			if err != nil {
+				t.Errorf("Deserialize for %s FAILED: %v\n", test.name, err)
+			} else if result := hex.EncodeToString(transaction.Serialize()); result != raw {
+				t.Errorf("Deserialize for %s FAILED. Expected %s, got %s\n", test.name, raw, result)
+			}
+		}
+	}
+
+	if _, err := tx.Deserialize(decode(signTests[0].unsigned[:100])); err == nil {
+		t.Errorf("Deserialize of a truncated transaction passed, should've failed: FAIL\n")
+	}
+
+	// 0x7fffffff items in 5 bytes
+	if _, err := tx.DeserializeWitness(decode("feffffff7f")); err == nil {
+		t.Errorf("DeserializeWitness with a huge item count passed, should've failed: FAIL\n")
+	}
+	if witness, err := tx.DeserializeWitness(decode("0201aa00")); err != nil || len(witness) != 2 {
+		t.Errorf("DeserializeWitness FAILED: %d items, %v\n", len(witness), err)
+	}
+}
+
+func TestSignTaproot(t *testing.T) {
+	privateKey := keys.FromBigInt(big.NewInt(1), chaincfg.MainNet)
+	prevouts := []tx.Output{{Value: 100000, Script: decode(privateKey.ToScriptTaproot())}}
+
+	for _, hashType := range []byte{tx.SigHashAll, tx.SigHashSingle | tx.SigHashAnyoneCanPay} {
+		transaction, _ := tx.Deserialize(decode(signTests[1].unsigned))
+		if _, err := transaction.Sign(prevouts, []keys.PrivateKey{privateKey}, hashType); err != nil {
+			t.Errorf("Sign for P2TR %02x FAILED: %v\n", hashType, err)
+			continue
+		}
+
+		signature := transaction.Inputs[0].Witness[0]
+		sighashType := tx.SigHashDefault
+		if len(signature) == 65 {
+			signature, sighashType = signature[:64], signature[64]
+		}
+
+		expected := hashType
+		if hashType == tx.SigHashAll {
+			expected = tx.SigHashDefault
+		}
+
+		sighash, _ := transaction.SigHashTaproot(0, prevouts, sighashType)
+		if sighashType != expected {
+			t.Errorf("Sign for P2TR %02x FAILED. Got sighash type %02x\n", hashType, sighashType)
+		} else if err := keys.VerifySchnorr(privateKey.TaprootOutputKey(), sighash, signature); err != nil {
+			t.Errorf("Sign for P2TR %02x FAILED: %v\n", hashType, err)
+		} else {
+			t.Logf("Sign for P2TR passed: %02x\n", hashType)
+		}
+	}
+}
+
+// BIP341 keyPathSpending: a 9-input transaction signed with every sighash type
+const bip341Unsigned = "02000000097de20cbff686da83a54981d2b9bab3586f4ca7e48f57f5b55963115f3b334e9c010000000000000000d7b7cab57b1393ace2d064f4d4a2cb8af6def61273e127517d44759b6dafdd990000000000fffffffff8e1f583384333689228c5d28eac13366be082dc57441760d957275419a418420000000000fffffffff0689180aa63b30cb162a73c6d2a38b7eeda2a83ece74310fda0843ad604853b0100000000feffffffaa5202bdf6d8ccd2ee0f0202afbbb7461d9264a25e5bfd3c5a52ee1239e0ba6c0000000000feffffff956149bdc66faa968eb2be2d2faa29718acbfe3941215893a2a3446d32acd050000000000000000000e664b9773b88c09c32cb70a2a3e4da0ced63b7ba3b22f848531bbb1d5d5f4c94010000000000000000e9aa6b8e6c9de67619e6a3924ae25696bb7b694bb677a632a74ef7eadfd4eabf0000000000ffffffffa778eb6a263dc090464cd125c466b5a99667720b1c110468831d058aa1b82af10100000000ffffffff0200ca9a3b000000001976a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac807840cb0000000020ac9a87f5594be208f8532db38cff670c450ed2fea8fcdefcc9a663f78bab962b0065cd1d"
+
+var bip341Prevouts = []tx.Output{
+	{Value: 420000000, Script: decode("512053a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343")},
+	{Value: 462000000, Script: decode("5120147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3")},
+	{Value: 294000000, Script: decode("76a914751e76e8199196d454941c45d1b3a323f1433bd688ac")},
+	{Value: 504000000, Script: decode("5120e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e")},
+	{Value: 630000000, Script: decode("512091b64d5324723a985170e4dc5a0f84c041804f2cd12660fa5dec09fc21783605")},
+	{Value: 378000000, Script: decode("00147dd65592d0ab2fe0d0257d571abf032cd9db93dc")},
+	{Value: 672000000, Script: decode("512075169f4001aa68f15bbed28b218df1d0a62cbbcf1188c6665110c293c907b831")},
+	{Value: 546000000, Script: decode("5120712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5")},
+	{Value: 588000000, Script: decode("512077e30a5522dd9f894c3f8b8bd4c4b2cf82ca7da8a3ea6a239655c39c050ab220")},
+}
+
+func TestSigHashTaproot(t *testing.T) {
+	tests := []struct {
+		index    int
+		hashType byte
+		sighash  string
+	}{
+		{0, tx.SigHashSingle, "2514a6272f85cfa0f45eb907fcb0d121b808ed37c6ea160a5a9046ed5526d555"},
+		{1, tx.SigHashSingle | tx.SigHashAnyoneCanPay, "325a644af47e8a5a2591cda0ab0723978537318f10e6a63d4eed783b96a71a4d"},
+		{3, tx.SigHashAll, "bf013ea93474aa67815b1b6cc441d23b64fa310911d991e713cd34c7f5d46669"},
+		{4, tx.SigHashDefault, "4f900a0bae3f1446fd48490c2958b5a023228f01661cda3496a11da502a7f7ef"},
+		{6, tx.SigHashNone, "15f25c298eb5cdc7eb1d638dd2d45c97c4c59dcaec6679cfc16ad84f30876b85"},
+		{7, tx.SigHashNone | tx.SigHashAnyoneCanPay, "cd292de50313804dabe4685e83f923d2969577191a3e1d2882220dca88cbeb10"},
+		{8, tx.SigHashAll | tx.SigHashAnyoneCanPay, "cccb739eca6c13a8a89e6e5cd317ffe55669bbda23f2fd37b0f18755e008edd2"},
+	}
+
+	transaction, err := tx.Deserialize(decode(bip341Unsigned))
+	if err != nil {
+		t.Fatalf("Deserialize FAILED: %v\n", err)
+	}
+
+	for _, test := range tests {
+		sighash, err := transaction.SigHashTaproot(test.index, bip341Prevouts, test.hashType)
+		switch {
+		case err != nil:
+			t.Errorf("SigHashTaproot for input %d FAILED: %v\n", test.index, err)
+		case hex.EncodeToString(sighash) != test.sighash:
+			t.Errorf("SigHashTaproot for input %d, %02x FAILED. Expected %s, got %x\n", test.index, test.hashType, test.sighash, sighash)
+		default:
+			t.Logf("SigHashTaproot passed: input %d, %02x, %s\n", test.index, test.hashType, test.sighash)
+		}
+	}
+}